@@ -7,7 +7,7 @@ import (
 	"flag"
 	"fmt"
 	"go/ast"
-	"go/format"
+	"go/types"
 	"log"
 	"os"
 	"reflect"
@@ -15,13 +15,14 @@ import (
 	"text/template"
 
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/imports"
 
 	"github.com/nickng/csv"
 )
 
 var (
 	typename = flag.String("type", "", "name of type to store a CSV; must be set")
-	outfile  = flag.String("out", "parse_csv.generated.go", "filename of output file")
+	outfile  = flag.String("out", "parse_csv.generated.go", "filename of output file; the test is written to the same name with suffix _test.go")
 )
 
 func Usage() {
@@ -38,18 +39,25 @@ type Data struct {
 }
 
 type Field struct {
-	CSVFieldName    string // CSV record field name
-	StructFieldName string // Struct field name
+	CSVFieldName    string   // CSV record field name
+	Aliases         []string // Alternative CSV record field names
+	StructFieldName string   // Struct field name
+	Kind            string   // Kind of the struct field: string, bool, int, uint, float or duration
+	BitSize         int      // Bit size to parse numeric values, 0 for int and uint
+	Conv            string   // Conversion from the parsed value to the struct field type, if needed
 }
 
 //go:embed parse_csv.go.tmpl
 var parseCSVTmpl string
 
+//go:embed parse_csv_test.go.tmpl
+var parseCSVTestTmpl string
+
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("gen: ")
-	flag.Parse()
 	flag.Usage = Usage
+	flag.Parse()
 
 	if *typename == "" {
 		flag.Usage()
@@ -57,7 +65,7 @@ func main() {
 	}
 
 	cfg := &packages.Config{
-		Mode:  packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+		Mode:  packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
 		Tests: false,
 	}
 	pkgs, err := packages.Load(cfg)
@@ -67,15 +75,43 @@ func main() {
 	if len(pkgs) != 1 {
 		log.Fatalf("error: %d packages found", len(pkgs))
 	}
-	rowType, err := analyseType(*typename, pkgs[0])
+	code, test, err := generate(pkgs[0], *typename)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := os.WriteFile(*outfile, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+	testfile := strings.TrimSuffix(*outfile, ".go") + "_test.go"
+	if err := os.WriteFile(testfile, test, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate returns the formatted code to parse CSV records
+// to the struct type typename in pkg, and the formatted code of a test
+// that checks the generated code is up to date with the csv tags of
+// the struct type.
+func generate(pkg *packages.Package, typename string) (code, test []byte, err error) {
+	rowType, err := analyseType(typename, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	var d Data
-	d.TypeName = *typename
-	d.Package = pkgs[0].Name
+	d.TypeName = typename
+	d.Package = pkg.Name
+	headers := make(map[string]string) // Struct field name of each header value
 	for _, field := range rowType.Fields.List {
+		if len(field.Names) == 0 {
+			// The fields promoted from an embedded struct are read as
+			// fields of the row, which the generated code does not do.
+			t := pkg.TypesInfo.TypeOf(field.Type)
+			if field.Tag != nil || t != nil && hasTaggedFields(t, make(map[types.Type]bool)) {
+				return nil, nil, fmt.Errorf("field %s: unsupported embedded field with csv tags", types.ExprString(field.Type))
+			}
+			continue
+		}
 		if field.Tag == nil {
 			continue
 		}
@@ -85,24 +121,136 @@ func main() {
 		}
 		csvTag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("csv")
 		tag := csv.ParseTag(csvTag)
-		d.Fields = append(d.Fields, Field{
-			CSVFieldName:    tag.FieldHeader,
-			StructFieldName: field.Names[0].Name,
-		})
+		if tag.FieldHeader == "" {
+			continue
+		}
+		if err := analyseOptions(tag); err != nil {
+			return nil, nil, fmt.Errorf("field %s: %w", field.Names[0].Name, err)
+		}
+		for _, header := range tag.Headers() {
+			if name, exists := headers[header]; exists {
+				return nil, nil, fmt.Errorf("field %s: unsupported header %q shared with field %s", field.Names[0].Name, header, name)
+			}
+			headers[header] = field.Names[0].Name
+		}
+		f, err := analyseField(pkg, field.Type)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %s: %w", field.Names[0].Name, err)
+		}
+		f.CSVFieldName = tag.FieldHeader
+		f.Aliases = tag.Aliases
+		f.StructFieldName = field.Names[0].Name
+		d.Fields = append(d.Fields, f)
+	}
+
+	if code, err = execute(parseCSVTmpl, d); err != nil {
+		return nil, nil, err
+	}
+	if test, err = execute(parseCSVTestTmpl, d); err != nil {
+		return nil, nil, err
 	}
+	return code, test, nil
+}
 
-	tmpl := template.Must(template.New("").Parse(parseCSVTmpl))
+// execute returns the formatted code of the template text with data d.
+func execute(text string, d Data) ([]byte, error) {
+	tmpl := template.Must(template.New("").Parse(text))
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, d); err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	bs, err := format.Source(buf.Bytes())
-	if err != nil {
-		log.Fatal(err)
+	// Format the code and remove unused imports.
+	return imports.Process("", buf.Bytes(), nil)
+}
+
+// analyseOptions returns an error for the first option of tag that
+// changes how a record field is read, which the generated code does not
+// implement. The options only used to write records are allowed.
+func analyseOptions(tag csv.Tag) error {
+	if tag.Options == "" {
+		return nil
 	}
-	if err := os.WriteFile(*outfile, bs, 0644); err != nil {
-		log.Fatal(err)
+	for _, opt := range strings.Split(tag.Options, ",") {
+		key, _, _ := strings.Cut(opt, "=")
+		switch key {
+		case "fmt", "order":
+		default:
+			return fmt.Errorf("unsupported option %q", opt)
+		}
+	}
+	return nil
+}
+
+// hasTaggedFields returns whether t is a struct, or pointer to a
+// struct, with a field that has a csv tag, including the fields of its
+// embedded structs. The types in seen are not checked again.
+func hasTaggedFields(t types.Type, seen map[types.Type]bool) bool {
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < st.NumFields(); i++ {
+		if _, tagged := reflect.StructTag(st.Tag(i)).Lookup("csv"); tagged {
+			return true
+		}
+		if st.Field(i).Embedded() && hasTaggedFields(st.Field(i).Type(), seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyseField returns a Field with the parsing information of
+// the struct field type expr, which must have a basic underlying type.
+func analyseField(pkg *packages.Package, expr ast.Expr) (Field, error) {
+	t := pkg.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return Field{}, fmt.Errorf("unknown type %s", types.ExprString(expr))
+	}
+	typeName := types.TypeString(t, types.RelativeTo(pkg.Types))
+	if types.TypeString(t, nil) == "time.Duration" {
+		return Field{Kind: "duration"}, nil
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return Field{}, fmt.Errorf("unsupported type %s", typeName)
+	}
+	var (
+		f          Field
+		parsedType string // Type of the value returned by the parse function
+	)
+	switch basic.Kind() {
+	case types.String:
+		f.Kind, parsedType = "string", "string"
+	case types.Bool:
+		f.Kind, parsedType = "bool", "bool"
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		f.Kind, parsedType = "int", "int64"
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		f.Kind, parsedType = "uint", "uint64"
+	case types.Float32, types.Float64:
+		f.Kind, parsedType = "float", "float64"
+	default:
+		return Field{}, fmt.Errorf("unsupported type %s", typeName)
+	}
+	switch basic.Kind() {
+	case types.Int8, types.Uint8:
+		f.BitSize = 8
+	case types.Int16, types.Uint16:
+		f.BitSize = 16
+	case types.Int32, types.Uint32, types.Float32:
+		f.BitSize = 32
+	case types.Int64, types.Uint64, types.Float64:
+		f.BitSize = 64
+	}
+	if typeName != parsedType {
+		f.Conv = typeName
 	}
+	return f, nil
 }
 
 func analyseType(typename string, pkg *packages.Package) (*ast.StructType, error) {
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var (
+	// testdataDir is the directory of the example package and CSV file.
+	testdataDir = filepath.Join("..", "..", "testdata")
+	// typedDir is the directory of a package with non-string fields.
+	typedDir = filepath.Join("testdata", "typed")
+)
+
+func loadPackage(t *testing.T, dir string) *packages.Package {
+	t.Helper()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg)
+	if err != nil {
+		t.Fatalf("expected no error loading package but got %v", err)
+	}
+	if want, got := 1, len(pkgs); want != got {
+		t.Fatalf("expected %d package but got %d", want, got)
+	}
+	return pkgs[0]
+}
+
+func TestGenerate_golden(t *testing.T) {
+	testCases := [...]struct {
+		name string
+		dir  string
+	}{
+		{name: "example", dir: testdataDir},
+		{name: "typed", dir: typedDir},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testGolden(t, tc.dir, filepath.Join("testdata", tc.name))
+		})
+	}
+}
+
+// testGolden checks that the generated code and test for Row in dir
+// are the same as the golden files name.golden and name_test.golden.
+func testGolden(t *testing.T, dir, name string) {
+	code, test, err := generate(loadPackage(t, dir), "Row")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	checkGolden(t, name+".golden", code)
+	checkGolden(t, name+"_test.golden", test)
+}
+
+// checkGolden checks that got is the same as the golden file.
+func checkGolden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatalf("expected no error updating golden file but got %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("expected no error reading golden file but got %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("generated code does not match %s (run go test -update to update):\n%s", golden, got)
+	}
+}
+
+// exampleMain reads the CSV file given as argument with the generated
+// parser of example.Row and prints every row.
+const exampleMain = `package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	example "gentest/example"
+)
+
+func main() {
+	f, err := os.Open(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	p := example.NewRowCSVParser(csv.NewReader(f))
+	for {
+		row, err := p.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Fatal(err)
+		}
+		fmt.Printf("%+v\n", row)
+	}
+}
+`
+
+// writeModule writes a module to a temporary directory with exampleMain,
+// a copy of the package in dir as package example, and the given files
+// of package example, which replace the copied files of the same name.
+// It returns the directory of the module.
+//
+// The module requires github.com/nickng/csv from this repository.
+func writeModule(t *testing.T, dir string, example map[string][]byte) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping compiling generated code in short mode")
+	}
+	tmp := t.TempDir()
+	srcs, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod := "module gentest\n\ngo 1.23\n\n" +
+		"require github.com/nickng/csv v0.0.0\n\n" +
+		"replace github.com/nickng/csv => " + root + "\n"
+	files := map[string][]byte{
+		"go.mod":  []byte(mod),
+		"go.sum":  sum,
+		"main.go": []byte(exampleMain),
+	}
+	for _, src := range srcs {
+		bs, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[filepath.Join("example", filepath.Base(src))] = bs
+	}
+	for name, bs := range example {
+		files[filepath.Join("example", name)] = bs
+	}
+	for name, bs := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(tmp, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmp, name), bs, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmp
+}
+
+// runGenerated compiles the generated code with a copy of the package
+// in dir and runs exampleMain with the CSV file csvFile.
+func runGenerated(t *testing.T, dir string, generated []byte, csvFile string) (string, error) {
+	t.Helper()
+	tmp := writeModule(t, dir, map[string][]byte{"parse_csv.generated.go": generated})
+	csvPath, err := filepath.Abs(csvFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "run", ".", csvPath)
+	cmd.Dir = tmp
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestGenerate_run(t *testing.T) {
+	generated, _, err := generate(loadPackage(t, testdataDir), "Row")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	out, err := runGenerated(t, testdataDir, generated, filepath.Join(testdataDir, "example.csv"))
+	if err != nil {
+		t.Fatalf("expected no error running generated code but got %v:\n%s", err, out)
+	}
+	// Baz is not tagged so it is never assigned.
+	if want, got := "{Foo:1 Bar:2 Baz:}\n{Foo:3 Bar:2 Baz:}\n", out; want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestGenerate_typed(t *testing.T) {
+	generated, _, err := generate(loadPackage(t, typedDir), "Row")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	out, err := runGenerated(t, typedDir, generated, filepath.Join(typedDir, "typed.csv"))
+	if err != nil {
+		t.Fatalf("expected no error running generated code but got %v:\n%s", err, out)
+	}
+	expected := "{Name:alice Age:42 Small:-8 Count:3 Size:18446744073709551615 Price:9.99 Ratio:0.5 Active:true Wait:1m30s}\n" +
+		"{Name:bob Age:7 Small:0 Count:0 Size:1 Price:0.1 Ratio:1 Active:false Wait:0s}\n"
+	if want, got := expected, out; want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+
+	out, err = runGenerated(t, typedDir, generated, filepath.Join(typedDir, "invalid.csv"))
+	if err == nil {
+		t.Fatalf("expected error running generated code with invalid values but got none")
+	}
+	if want, got := `invalid value "forty-two" for field Age`, out; !strings.Contains(got, want) {
+		t.Fatalf("expected output to contain %q but got %q", want, got)
+	}
+}
+
+func TestGenerate_unsupportedType(t *testing.T) {
+	pkg := loadPackage(t, filepath.Join("testdata", "unsupported"))
+	if _, _, err := generate(pkg, "Row"); err == nil {
+		t.Fatalf("expected error for unsupported field type but got none")
+	}
+}
+
+func TestGenerate_embedded(t *testing.T) {
+	pkg := loadPackage(t, filepath.Join("testdata", "embedded"))
+	_, _, err := generate(pkg, "Row")
+	if err == nil {
+		t.Fatalf("expected error for embedded struct with tagged fields but got none")
+	}
+	if want, got := "field *Extra: unsupported embedded field with csv tags", err.Error(); want != got {
+		t.Fatalf("expected error %q but got %q", want, got)
+	}
+}
+
+func TestGenerate_unsupportedOption(t *testing.T) {
+	pkg := loadPackage(t, filepath.Join("testdata", "unsupportedoption"))
+	_, _, err := generate(pkg, "Row")
+	if err == nil {
+		t.Fatalf("expected error for unsupported tag option but got none")
+	}
+	if want, got := `field Country: unsupported option "default=US"`, err.Error(); want != got {
+		t.Fatalf("expected error %q but got %q", want, got)
+	}
+}
+
+func TestGenerate_duplicateHeader(t *testing.T) {
+	pkg := loadPackage(t, filepath.Join("testdata", "duplicateheader"))
+	_, _, err := generate(pkg, "Row")
+	if err == nil {
+		t.Fatalf("expected error for header value of more than one field but got none")
+	}
+	if want, got := `field Parsed: unsupported header "foo" shared with field Raw`, err.Error(); want != got {
+		t.Fatalf("expected error %q but got %q", want, got)
+	}
+}
+
+// goTest runs the tests of package example in the module in dir.
+func goTest(dir string) (string, error) {
+	cmd := exec.Command("go", "test", "./example")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestGenerate_test(t *testing.T) {
+	generated, test, err := generate(loadPackage(t, typedDir), "Row")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	tmp := writeModule(t, typedDir, map[string][]byte{
+		"parse_csv.generated.go":      generated,
+		"parse_csv.generated_test.go": test,
+	})
+	if out, err := goTest(tmp); err != nil {
+		t.Fatalf("expected no error testing generated code but got %v:\n%s", err, out)
+	}
+}
+
+func TestGenerate_testOutOfDate(t *testing.T) {
+	generated, test, err := generate(loadPackage(t, typedDir), "Row")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	row, err := os.ReadFile(filepath.Join(typedDir, "row.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rename a header value without generating the code again.
+	tmp := writeModule(t, typedDir, map[string][]byte{
+		"parse_csv.generated.go":      generated,
+		"parse_csv.generated_test.go": test,
+		"row.go":                      bytes.Replace(row, []byte(`csv:"age"`), []byte(`csv:"years"`), 1),
+	})
+	out, err := goTest(tmp)
+	if err == nil {
+		t.Fatalf("expected error testing out of date generated code but got none")
+	}
+	if want, got := "is out of date", out; !strings.Contains(got, want) {
+		t.Fatalf("expected output to contain %q but got %q", want, got)
+	}
+}
@@ -0,0 +1,6 @@
+package duplicateheader
+
+type Row struct {
+	Raw    string `csv:"foo"`
+	Parsed int    `csv:"foo"`
+}
@@ -0,0 +1,21 @@
+package embedded
+
+// Base has no tagged fields, so embedding it is supported.
+type Base struct {
+	ID string
+}
+
+type Inner struct {
+	Note string `csv:"note"`
+}
+
+// Extra has tagged fields promoted from Inner.
+type Extra struct {
+	Inner
+}
+
+type Row struct {
+	Base
+	*Extra
+	Name string `csv:"name"`
+}
@@ -0,0 +1,17 @@
+package typed
+
+import "time"
+
+type Count int
+
+type Row struct {
+	Name   string        `csv:"name"`
+	Age    int           `csv:"age"`
+	Small  int8          `csv:"small"`
+	Count  Count         `csv:"count"`
+	Size   uint64        `csv:"size"`
+	Price  float64       `csv:"price"`
+	Ratio  float32       `csv:"ratio"`
+	Active bool          `csv:"active"`
+	Wait   time.Duration `csv:"wait"`
+}
@@ -0,0 +1,6 @@
+package unsupported
+
+type Row struct {
+	Name  string   `csv:"name"`
+	Items []string `csv:"items"`
+}
@@ -0,0 +1,6 @@
+package unsupportedoption
+
+type Row struct {
+	Name    string `csv:"name,order=1"`
+	Country string `csv:"country,default=US"`
+}
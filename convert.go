@@ -0,0 +1,557 @@
+package csv
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshaler is the interface implemented by types that can unmarshal
+// a record field value into themselves.
+type Unmarshaler interface {
+	UnmarshalCSV(string) error
+}
+
+// Marshaler is the interface implemented by types that can marshal
+// themselves into a record field value.
+type Marshaler interface {
+	MarshalCSV() (string, error)
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+	stringType      = reflect.TypeOf("")
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+)
+
+// isSupportedType reports whether a struct field of type t can store
+// a record field value. Types are matched by kind, so a named type such
+// as `type Currency string` is supported like its underlying type.
+func isSupportedType(t reflect.Type) bool {
+	if t == timeType || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return true
+	}
+	if t.Kind() == reflect.Pointer {
+		return t.Elem().Kind() != reflect.Pointer && isSupportedType(t.Elem())
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	case reflect.Interface:
+		return stringType.Implements(t)
+	}
+	return false
+}
+
+// isSupportedField reports whether a struct field of type t with the
+// tag can store a record field value. Fields with the split= option
+// should be a slice of a supported non-slice, non-pointer type.
+func isSupportedField(t reflect.Type, tag Tag) bool {
+	switch {
+	case tag.JSON:
+		return true
+	case tag.Split != "":
+		if t.Kind() != reflect.Slice {
+			return false
+		}
+		elem := t.Elem()
+		return elem.Kind() != reflect.Slice && elem.Kind() != reflect.Pointer && isSupportedType(elem)
+	}
+	return isSupportedType(t)
+}
+
+// isWritableType reports whether a struct field of type t can be
+// written as a record field value by formatValue. Interface types are
+// formatted by their dynamic value, so they are assumed writable.
+func isWritableType(t reflect.Type) bool {
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) || t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return isWritableType(t.Elem())
+	case reflect.Interface, reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// isWritableField reports whether a struct field of type t with the
+// tag can be written as a record field value. Fields with the json
+// option are written with json.Marshal, and fields with the split=
+// option are written element by element.
+func isWritableField(t reflect.Type, tag Tag) bool {
+	switch {
+	case tag.JSON:
+		return true
+	case tag.Split != "" && t.Kind() == reflect.Slice:
+		return isWritableType(t.Elem())
+	}
+	return isWritableType(t)
+}
+
+// isNumberType reports whether t, or the type t points to, is an
+// integer, floating-point or complex type parsed with strconv.
+func isNumberType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+// setter parses a raw record field value s and stores the result in v.
+type setter func(v reflect.Value, s string) error
+
+// newSetter returns a setter for struct fields of type t, which parses
+// s according to the kind of t. The tag of the struct field customises
+// how s is parsed. If t is a pointer, an empty s sets v to nil,
+// otherwise v is set to a newly allocated value parsed from s.
+//
+// If the tag has the json option, s is unmarshalled into v as JSON,
+// and an empty s sets v to the zero value.
+//
+// If the tag has the split= option, s is split by the separator and
+// each value is parsed as an element of v. An empty s sets v to nil
+// rather than an empty slice, so it is the same as the zero value.
+func newSetter(t reflect.Type, tag Tag) setter {
+	if tag.Split != "" && t.Kind() == reflect.Slice {
+		elemTag := tag
+		elemTag.Split = ""
+		setElem := newSetter(t.Elem(), elemTag)
+		return func(v reflect.Value, s string) error {
+			if s == "" {
+				v.SetZero()
+				return nil
+			}
+			values := strings.Split(s, tag.Split)
+			slice := reflect.MakeSlice(t, len(values), len(values))
+			for i, value := range values {
+				if err := setElem(slice.Index(i), value); err != nil {
+					return err
+				}
+			}
+			v.Set(slice)
+			return nil
+		}
+	}
+	if tag.JSON {
+		return func(v reflect.Value, s string) error {
+			if s == "" {
+				v.SetZero()
+				return nil
+			}
+			ptr := reflect.New(t)
+			if err := json.Unmarshal([]byte(s), ptr.Interface()); err != nil {
+				return err
+			}
+			v.Set(ptr.Elem())
+			return nil
+		}
+	}
+	if t.Kind() == reflect.Pointer {
+		setElem := newSetter(t.Elem(), tag)
+		return func(v reflect.Value, s string) error {
+			if s == "" {
+				v.SetZero()
+				return nil
+			}
+			elem := reflect.New(t.Elem())
+			if err := setElem(elem.Elem(), s); err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		}
+	}
+	if tag.Enum != "" {
+		valueTag := tag
+		valueTag.Enum = ""
+		set := newSetter(t, valueTag)
+		literals := strings.Split(tag.Enum, "|")
+		return func(v reflect.Value, s string) error {
+			literal, ok := matchEnum(s, literals, tag.EnumFold)
+			if !ok {
+				return fmt.Errorf("%q not in %q: %w", s, literals, ErrInvalidEnum)
+			}
+			return set(v, literal)
+		}
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return func(v reflect.Value, s string) error {
+			if !v.CanAddr() {
+				return ErrFieldNotAssignable
+			}
+			return v.Addr().Interface().(Unmarshaler).UnmarshalCSV(s)
+		}
+	}
+	if t == timeType {
+		layout := tag.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return func(v reflect.Value, s string) error {
+			t, err := time.Parse(layout, s)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+	if t == durationType {
+		return func(v reflect.Value, s string) error {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value, s string) error {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		return func(v reflect.Value, s string) error {
+			b, err := parseBool(s, tag)
+			if err != nil {
+				return err
+			}
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := t.Bits()
+		return func(v reflect.Value, s string) error {
+			n, err := strconv.ParseInt(s, 10, bits)
+			if err != nil {
+				return err
+			}
+			v.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bits := t.Bits()
+		return func(v reflect.Value, s string) error {
+			n, err := strconv.ParseUint(s, 10, bits)
+			if err != nil {
+				return err
+			}
+			v.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		bits := t.Bits()
+		return func(v reflect.Value, s string) error {
+			n, err := strconv.ParseFloat(s, bits)
+			if err != nil {
+				return err
+			}
+			v.SetFloat(n)
+			return nil
+		}
+	case reflect.Complex64, reflect.Complex128:
+		bits := t.Bits()
+		return func(v reflect.Value, s string) error {
+			n, err := strconv.ParseComplex(s, bits)
+			if err != nil {
+				return err
+			}
+			v.SetComplex(n)
+			return nil
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return func(v reflect.Value, s string) error {
+				v.SetBytes([]byte(s))
+				return nil
+			}
+		}
+	case reflect.Interface:
+		// Values are always stored as string, there is no type inference.
+		return func(v reflect.Value, s string) error {
+			v.Set(reflect.ValueOf(s))
+			return nil
+		}
+	}
+	return func(reflect.Value, string) error {
+		return ErrFieldNotAssignable
+	}
+}
+
+// hasFloat reports whether a struct field of type t with the tag stores
+// floating-point or complex numbers parsed from the record field value,
+// directly, through a pointer, or as elements with the split= option.
+func hasFloat(t reflect.Type, tag Tag) bool {
+	if tag.JSON {
+		return false
+	}
+	if tag.Split != "" && t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+// rejectNonFinite returns a setter that returns ErrNonFinite if s is NaN
+// or an infinity, and otherwise stores s with set. The values with the
+// split= option are checked one by one.
+func rejectNonFinite(t reflect.Type, tag Tag, set setter) setter {
+	return func(v reflect.Value, s string) error {
+		values := []string{s}
+		if tag.Split != "" && t.Kind() == reflect.Slice {
+			values = strings.Split(s, tag.Split)
+		}
+		for _, value := range values {
+			if isNonFinite(value) {
+				return ErrNonFinite
+			}
+		}
+		return set(v, s)
+	}
+}
+
+// isNonFinite reports whether s is parsed by strconv as a NaN or an
+// infinity, as a real number or as either part of a complex number.
+// Finite numbers too large for their type are rejected by strconv.
+func isNonFinite(s string) bool {
+	c, err := strconv.ParseComplex(s, 128)
+	if err != nil {
+		return false
+	}
+	re, im := real(c), imag(c)
+	return math.IsNaN(re) || math.IsInf(re, 0) || math.IsNaN(im) || math.IsInf(im, 0)
+}
+
+// inferredFloat matches the record field values inferred as float64.
+var inferredFloat = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// inferType converts a record field value s to int, float64 or bool
+// by its content, or otherwise keeps it as a string, following the
+// rules documented in WithInferTypes.
+func inferType(s string) any {
+	if n, err := strconv.Atoi(s); err == nil && strconv.Itoa(n) == s {
+		return n
+	}
+	if inferredFloat.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	if b, err := strconv.ParseBool(s); err == nil && len(s) > 1 {
+		return b
+	}
+	return s
+}
+
+// setInferred is the setter of interface struct fields with type
+// inference, which stores s converted by inferType.
+func setInferred(v reflect.Value, s string) error {
+	v.Set(reflect.ValueOf(inferType(s)))
+	return nil
+}
+
+// matchEnum returns the literal that s matches. If fold is true, s is
+// matched case-insensitively and ignoring leading and trailing white
+// space, and the matching literal is returned as written in the tag.
+func matchEnum(s string, literals []string, fold bool) (string, bool) {
+	if fold {
+		s = strings.TrimSpace(s)
+	}
+	for _, literal := range literals {
+		if s == literal || (fold && strings.EqualFold(s, literal)) {
+			return literal, true
+		}
+	}
+	return "", false
+}
+
+// parseBool parses s as a bool. If the tag specifies the true or false
+// literals, only those are accepted for the respective value, otherwise
+// the literals accepted by strconv.ParseBool are used.
+func parseBool(s string, tag Tag) (bool, error) {
+	if tag.True == "" && tag.False == "" {
+		return strconv.ParseBool(s)
+	}
+	if matchLiteral(s, tag.True, true) {
+		return true, nil
+	}
+	if matchLiteral(s, tag.False, false) {
+		return false, nil
+	}
+	return false, ErrInvalidBool
+}
+
+// matchLiteral reports whether s is one of the "|"-separated literals.
+// If literals is empty, s is matched against the strconv.ParseBool
+// literals for value.
+func matchLiteral(s, literals string, value bool) bool {
+	if literals == "" {
+		b, err := strconv.ParseBool(s)
+		return err == nil && b == value
+	}
+	for _, literal := range strings.Split(literals, "|") {
+		if s == literal {
+			return true
+		}
+	}
+	return false
+}
+
+// formatValue formats the struct field value v as a raw record field
+// value. It is the inverse of the setter of v.
+func formatValue(v reflect.Value, tag Tag) (string, error) {
+	if tag.JSON {
+		bs, err := json.Marshal(v.Interface())
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}
+	if tag.Split != "" && v.Kind() == reflect.Slice {
+		elemTag := tag
+		elemTag.Split = ""
+		values := make([]string, v.Len())
+		for i := range values {
+			value, err := formatValue(v.Index(i), elemTag)
+			if err != nil {
+				return "", err
+			}
+			values[i] = value
+		}
+		return strings.Join(values, tag.Split), nil
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		return formatValue(v.Elem(), tag)
+	}
+	if m, ok := v.Interface().(Marshaler); ok {
+		return m.MarshalCSV()
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(Marshaler); ok {
+			return m.MarshalCSV()
+		}
+	}
+	if v.Type() == timeType {
+		layout := tag.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return v.Interface().(time.Time).Format(layout), nil
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+	if tag.Format != "" && isNumberType(v.Type()) {
+		return fmt.Sprintf(tag.Format, numberValue(v)), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return formatBool(v.Bool(), tag), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+	}
+	return "", ErrFieldNotWritable
+}
+
+// numberValue returns the value of v of a numeric kind as the
+// predeclared type of the same kind and size, to be formatted with the
+// fmt= tag option without the methods of v, such as String.
+func numberValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32:
+		return float32(v.Float())
+	case reflect.Float64:
+		return v.Float()
+	case reflect.Complex64:
+		return complex64(v.Complex())
+	case reflect.Complex128:
+		return v.Complex()
+	}
+	return nil
+}
+
+// isValidFormat reports whether the fmt= tag option of a struct field
+// of type t is valid, i.e. t is numeric and the format has one verb
+// that can format it.
+func isValidFormat(t reflect.Type, tag Tag) bool {
+	if tag.JSON || tag.Split != "" || !isNumberType(t) {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := fmt.Sprintf(tag.Format, numberValue(reflect.New(t).Elem()))
+	return !strings.Contains(s, "%!")
+}
+
+// formatBool formats b using the first true or false literal of the
+// tag, or strconv.FormatBool if the tag does not specify one.
+func formatBool(b bool, tag Tag) string {
+	literals := tag.False
+	if b {
+		literals = tag.True
+	}
+	if literals == "" {
+		return strconv.FormatBool(b)
+	}
+	literal, _, _ := strings.Cut(literals, "|")
+	return literal
+}
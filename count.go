@@ -0,0 +1,27 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// CountRecords reads all the remaining records of r without parsing
+// them and returns the number of records, excluding the header.
+// An empty file has no header and no records. Errors other than io.EOF
+// are returned as a *ReadError, with the number of records read so far.
+func CountRecords(r *csv.Reader) (int, error) {
+	reuseRecord := r.ReuseRecord
+	r.ReuseRecord = true
+	defer func() { r.ReuseRecord = reuseRecord }()
+	records := 0
+	for {
+		if _, err := r.Read(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return max(records-1, 0), &ReadError{Record: records + 1, Err: err}
+		}
+		records++
+	}
+	return max(records-1, 0), nil
+}
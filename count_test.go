@@ -0,0 +1,33 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCountRecords(t *testing.T) {
+	testCases := [...]struct {
+		name          string
+		data          string
+		expectedCount int
+		expectedErr   error
+	}{
+		{name: "empty", data: "", expectedCount: 0},
+		{name: "header only", data: "foo,bar,baz\n", expectedCount: 0},
+		{name: "records", data: exampleCSV, expectedCount: 2},
+		{name: "invalid record", data: "foo,bar\n1,2\n3\n", expectedCount: 1, expectedErr: csv.ErrFieldCount},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			count, err := CountRecords(csv.NewReader(strings.NewReader(tc.data)))
+			if want, got := tc.expectedErr, err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if want, got := tc.expectedCount, count; want != got {
+				t.Fatalf("expected %d records but got %d", want, got)
+			}
+		})
+	}
+}
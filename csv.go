@@ -1,116 +1,1227 @@
 package csv
 
 import (
+	"bufio"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"maps"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // Reader is a structured data reader from CSV.
 type Reader[T any] struct {
-	rd           *csv.Reader // Underlying CSV reader
-	fieldIndex   map[int]int // Converts record field index to struct field index
+	rd           *csv.Reader       // Underlying CSV reader
+	fields       []structField     // Struct fields of T, by struct field index
+	fieldIndex   [][]int           // Converts record field index to struct field indices
+	plan         []fieldPlan       // Compiled from fieldIndex, by record field index
+	rest         int               // Struct field index of the rest field, or noField
+	numbers      *strings.Replacer // Normalises numbers of numeric struct fields, or nil
+	header       []string
 	parsedHeader bool
+	skippedLines bool
+	peeked       []string      // Record buffered by Peek, or nil
+	last         []string      // Last record stored, for Extras
+	present      []bool        // Struct fields set from the last record stored, for Present
+	saved        reflect.Value // Row restored after a record error, with WithRecordErrorHandler
+	records      int           // Number of records read from rd
+	line         int           // Line where the last record read without error starts
+	pending      []*csv.Reader // Files read after rd, by NewMultiReader
+	columns      int           // Number of record fields expected in strict columns mode
+	opts         readerOptions
 }
 
 // NewReader creates a new structured data reader from an underlying
 // raw CSV record reader. It returns error if the generic type T is
 // not a valid type to stored the parsed data.
-func NewReader[T any](r *csv.Reader) (*Reader[T], error) {
-	csvReader := &Reader[T]{rd: r}
+//
+// Options that configure the underlying CSV reader, such as WithComma,
+// are applied to r before any record is read.
+func NewReader[T any](r *csv.Reader, opts ...ReaderOption) (*Reader[T], error) {
+	return newReader[T](r, nil, opts)
+}
+
+// NewMultiReader creates a new structured data reader that reads the
+// records of each of readers in turn, as if they were a single file.
+// The header of each file is read and matched to the struct fields
+// again, so the files may have their columns in different orders, but
+// T is validated only once. Line and the errors of the records refer
+// to the file being read.
+//
+// The options apply to all the files, as if each file was given to
+// Reset when the file before it ends.
+func NewMultiReader[T any](readers []*csv.Reader, opts ...ReaderOption) (*Reader[T], error) {
+	if len(readers) == 0 {
+		return NewReader[T](csv.NewReader(strings.NewReader("")), opts...)
+	}
+	r, err := NewReader[T](readers[0], opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.pending = readers[1:]
+	return r, nil
+}
+
+// newReader creates a new structured data reader from r, or if src is
+// not nil, from a new CSV reader reading from src, which is used by
+// the options that read directly.
+func newReader[T any](r *csv.Reader, src io.Reader, opts []ReaderOption) (*Reader[T], error) {
+	csvReader := &Reader[T]{}
+	for _, opt := range opts {
+		opt(&csvReader.opts)
+	}
+	if src != nil {
+		if size := csvReader.opts.bufferSize; size > 0 {
+			src = bufio.NewReaderSize(src, size)
+		}
+		if enc := csvReader.opts.encoding; enc != nil {
+			src = enc.NewDecoder().Reader(src)
+		}
+		r = csv.NewReader(src)
+	} else if csvReader.opts.bufferSize > 0 {
+		return nil, fmt.Errorf("WithBufferSize: %w", ErrUnsupportedOption)
+	} else if csvReader.opts.encoding != nil {
+		return nil, fmt.Errorf("WithEncoding: %w", ErrUnsupportedOption)
+	}
+	csvReader.rd = r
+	for _, configure := range csvReader.opts.configure {
+		configure(r)
+	}
+	csvReader.numbers = csvReader.opts.numberReplacer()
 	if err := csvReader.validateFields(); err != nil {
 		return nil, err
 	}
+	csvReader.cacheFields()
+	if err := csvReader.checkDefaults(); err != nil {
+		return nil, err
+	}
+	if err := csvReader.applyHeaderMap(); err != nil {
+		return nil, err
+	}
+	if err := csvReader.applyTransforms(); err != nil {
+		return nil, err
+	}
+	if csvReader.opts.noHeader {
+		if err := csvReader.indexFields(); err != nil {
+			return nil, err
+		}
+		csvReader.parsedHeader = true
+	}
 	return csvReader, nil
 }
 
-var (
-	errNotPointer         = fmt.Errorf("fields should be a pointer")
-	errNotStructPointer   = fmt.Errorf("fields should be a pointer to a struct")
-	errFieldNotAssignable = fmt.Errorf("field is not assignable")
-)
+// Reset discards the state of the Reader and makes it read from r,
+// keeping the options and the struct fields of T. Reset does not
+// re-validate T, so a Reader can be reused cheaply for many files.
+// The header of r is read by the next Read, unless WithoutHeader is set.
+// The files not yet read of NewMultiReader are discarded.
+func (r *Reader[T]) Reset(rd *csv.Reader) {
+	for _, configure := range r.opts.configure {
+		configure(rd)
+	}
+	r.rd = rd
+	r.pending = nil
+	r.records = 0
+	r.line = 0
+	r.skippedLines = false
+	r.peeked = nil
+	r.last = nil
+	clear(r.present)
+	r.columns = 0
+	if r.opts.noHeader {
+		return // The struct fields are mapped by index, not by header.
+	}
+	r.fieldIndex = r.fieldIndex[:0]
+	r.plan = r.plan[:0]
+	r.header = nil
+	r.parsedHeader = false
+}
+
+// SetSchema replaces the header map of WithHeaderMap with headerMap,
+// which maps header values to the names of struct fields, overriding
+// the header values of their csv tags. A nil headerMap restores the
+// header values of the tags. It is useful with Reset to read files
+// with different header values into the same struct type, with the
+// header values known only at run time.
+//
+// If the header has been read, it is matched to the struct fields again,
+// so the next Read uses the new header map. SetSchema returns error,
+// and keeps the previous header map, if a struct field name is not a
+// field of T, or the header does not match the struct fields.
+func (r *Reader[T]) SetSchema(headerMap map[string]string) error {
+	previous := r.opts.headerMap
+	if err := r.applySchema(headerMap); err != nil {
+		// The previous header map was applied without error.
+		_ = r.applySchema(previous)
+		return err
+	}
+	return nil
+}
+
+// applySchema sets the header map of the struct fields and maps the
+// struct fields to the record fields again.
+func (r *Reader[T]) applySchema(headerMap map[string]string) error {
+	r.opts.headerMap = headerMap
+	r.cacheFields()
+	if err := r.applyHeaderMap(); err != nil {
+		return err
+	}
+	if err := r.applyTransforms(); err != nil {
+		return err
+	}
+	switch {
+	case r.opts.noHeader:
+		return r.indexFields()
+	case r.parsedHeader:
+		return r.parseHeader(r.header)
+	}
+	return nil
+}
 
 // validateFieldsType checks that the generic type T can be used to store
 // record field values of a CSV file. T should be a pointer to a struct.
-// All tagged fields should be string.
+// All tagged fields should be a string, []byte, bool, numeric type
+// (including complex64 and complex128), time.Time or time.Duration, or
+// a pointer to one of these types.
+// Tagged fields can also be an interface type that string implements,
+// such as any, which always store the record field value as a string.
 func (r *Reader[T]) validateFields() error {
 	var rowPtr T
-	rowPtrType := reflect.TypeOf(rowPtr) // reflect.Value of rowPtr
+	return validateRowType(reflect.TypeOf(rowPtr))
+}
+
+// validateRowType checks that rowPtrType is a pointer to a struct
+// with tagged fields of supported types and valid tag options. Tagged
+// fields should also be exported. All the problems found in the struct
+// fields are returned joined with errors.Join. More than one struct
+// field can have the same header value, and they are all set from the
+// same record field. The struct fields of untagged embedded structs are
+// checked as the struct fields of the struct, see structFields.
+func validateRowType(rowPtrType reflect.Type) error {
 	if rowPtrType.Kind() != reflect.Pointer {
-		return errNotPointer
+		return ErrNotPointer
 	}
 	rowStruct := rowPtrType.Elem()
 	if rowStruct.Kind() != reflect.Struct {
-		return errNotStructPointer
+		return ErrNotStructPointer
+	}
+	var (
+		errs []error
+		rest string // Name of the rest field
+	)
+	for _, f := range structFields(rowStruct) {
+		tag := ParseTag(f.Tag.Get("csv"))
+		if tag.Skip {
+			continue
+		}
+		if err := checkOptions(tag.Options); err != nil {
+			errs = append(errs, fmt.Errorf("invalid field %s: %w", f.Name, err))
+		}
+		if !f.IsExported() && (tag.FieldHeader != "" || tag.HasIndex || tag.Rest) {
+			errs = append(errs, fmt.Errorf("invalid field %s: %w", f.Name, ErrUnexportedField))
+			continue
+		}
+		if tag.Rest {
+			if rest != "" {
+				errs = append(errs, fmt.Errorf("fields %s and %s: %w", rest, f.Name, ErrDuplicateRest))
+			}
+			rest = f.Name
+			if f.Type.Kind() != reflect.Slice || f.Type.Elem().Kind() != reflect.String {
+				errs = append(errs, fmt.Errorf("invalid rest field %s: %w", f.Name, ErrFieldNotAssignable))
+			}
+			continue
+		}
+		if tag.FieldHeader != "" || tag.HasIndex {
+			if !isSupportedField(f.Type, tag) {
+				errs = append(errs, fmt.Errorf("invalid field %s: %w", f.Name, ErrFieldNotAssignable))
+			} else if tag.Default != "" {
+				if err := newSetter(f.Type, tag)(reflect.New(f.Type).Elem(), tag.Default); err != nil {
+					errs = append(errs, fmt.Errorf("field %s: %w %q: %w", f.Name, ErrInvalidDefault, tag.Default, err))
+				}
+			}
+			if tag.Format != "" && !isValidFormat(f.Type, tag) {
+				errs = append(errs, fmt.Errorf("field %s: %w %q", f.Name, ErrInvalidFormat, tag.Format))
+			}
+		}
 	}
-	for i := 0; i < rowStruct.NumField(); i++ {
-		f := rowStruct.Field(i)
+	return errors.Join(errs...)
+}
+
+// structFields returns the struct fields of rowStruct, where the
+// fields of untagged embedded structs, or pointers to structs, are
+// promoted in place of the embedded fields, like encoding/json. The
+// Index of every struct field is its index sequence in rowStruct, for
+// reflect.Value.FieldByIndex. Embedded pointers to structs that are
+// unexported, or to the structs they are embedded in, are not promoted.
+func structFields(rowStruct reflect.Type) []reflect.StructField {
+	return appendFields(nil, rowStruct, nil, make(map[reflect.Type]bool))
+}
+
+// appendFields appends the struct fields of the struct type t at index
+// to fields, skipping the struct types of outer embedded fields.
+func appendFields(fields []reflect.StructField, t reflect.Type, index []int, outer map[reflect.Type]bool) []reflect.StructField {
+	outer[t] = true
+	defer delete(outer, t)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		f.Index = append(slices.Clip(index), i)
+		if embedded := embeddedStruct(f); embedded != nil && !outer[embedded] {
+			fields = appendFields(fields, embedded, f.Index, outer)
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// embeddedStruct returns the struct type of f if f is an untagged
+// embedded struct, or pointer to a struct that can be allocated, or
+// nil otherwise.
+func embeddedStruct(f reflect.StructField) reflect.Type {
+	if !f.Anonymous {
+		return nil
+	}
+	if _, tagged := f.Tag.Lookup("csv"); tagged {
+		return nil
+	}
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		if !f.IsExported() {
+			return nil // A nil pointer could not be allocated.
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isSupportedType(t) {
+		return nil
+	}
+	return t
+}
+
+// fieldByIndex returns the struct field of v with the index sequence
+// index, allocating the nil embedded pointers to structs on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	if len(index) == 1 {
+		return v.Field(index[0])
+	}
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// structField is the information of a struct field of T, cached to
+// avoid reflection and tag parsing for every record.
+type structField struct {
+	name      string
+	index     []int // Index sequence in T, see structFields
+	exported  bool
+	typ       reflect.Type
+	tag       Tag
+	set       setter
+	transform func(string) string // Transforms record field values, or nil
+	number    bool                // Whether record field values are numbers
+}
+
+// cacheFields caches the name, parsed tag and setter of the struct
+// fields of T.
+func (r *Reader[T]) cacheFields() {
+	var rowPtr T
+	fields := structFields(reflect.TypeOf(rowPtr).Elem())
+	r.fields = make([]structField, len(fields))
+	r.rest = noField
+	for i, f := range fields {
 		tag := ParseTag(f.Tag.Get("csv"))
-		if tag.FieldHeader != "" {
-			if rowStruct.FieldByIndex([]int{i}).Type.Kind() != reflect.String {
-				return fmt.Errorf("invalid field %s: %w", rowStruct.Field(i).Name, errFieldNotAssignable)
+		set := newSetter(f.Type, tag)
+		if r.opts.inferTypes && f.Type.Kind() == reflect.Interface && !tag.JSON {
+			set = setInferred
+		}
+		if r.opts.rejectNonFinite && hasFloat(f.Type, tag) {
+			set = rejectNonFinite(f.Type, tag, set)
+		}
+		number := isNumberType(f.Type) && !tag.JSON && tag.Split == ""
+		r.fields[i] = structField{name: f.Name, index: f.Index, exported: f.IsExported(), typ: f.Type, tag: tag, set: set, number: number}
+		if tag.Rest {
+			r.rest = i
+		}
+	}
+}
+
+// checkDefaults checks the default values of the struct fields with
+// their setters, which validateRowType cannot do for the options that
+// wrap the setters, such as WithRejectNonFinite.
+func (r *Reader[T]) checkDefaults() error {
+	var errs []error
+	for _, sf := range r.fields {
+		if sf.tag.Default == "" || sf.tag.Skip || sf.tag.Rest {
+			continue
+		}
+		if err := sf.set(reflect.New(sf.typ).Elem(), sf.tag.Default); err != nil {
+			errs = append(errs, fmt.Errorf("field %s: %w %q: %w", sf.name, ErrInvalidDefault, sf.tag.Default, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyHeaderMap overrides the header values of the struct fields from
+// their tags with the header map of the WithHeaderMap option.
+func (r *Reader[T]) applyHeaderMap() error {
+	if len(r.opts.headerMap) == 0 {
+		return nil
+	}
+	nameToIndex := make(map[string]int)
+	for i, sf := range r.fields {
+		// The shallowest struct field of a name is the promoted one.
+		if j, exists := nameToIndex[sf.name]; !exists || len(sf.index) < len(r.fields[j].index) {
+			nameToIndex[sf.name] = i
+		}
+	}
+	fieldToHeader := make(map[string]string)
+	for _, header := range slices.Sorted(maps.Keys(r.opts.headerMap)) {
+		name := r.opts.headerMap[header]
+		i, exists := nameToIndex[name]
+		if !exists {
+			return fmt.Errorf("header %q: field %s: %w", header, name, ErrUnknownField)
+		}
+		if other, exists := fieldToHeader[name]; exists {
+			return fmt.Errorf("field %s from header %q and %q: %w", name, other, header, ErrDuplicateField)
+		}
+		fieldToHeader[name] = header
+		sf := &r.fields[i]
+		if !sf.exported {
+			return fmt.Errorf("invalid field %s: %w", name, ErrUnexportedField)
+		}
+		if !isSupportedField(sf.typ, sf.tag) {
+			return fmt.Errorf("invalid field %s: %w", name, ErrFieldNotAssignable)
+		}
+		sf.tag.FieldHeader, sf.tag.Aliases, sf.tag.Skip = header, nil, false
+	}
+	return nil
+}
+
+// applyTransforms sets the transform functions of the struct fields
+// from the WithFieldTransform options. A transform applies to every
+// struct field with the header value.
+func (r *Reader[T]) applyTransforms() error {
+	for _, ft := range r.opts.transforms {
+		found := false
+		for i := range r.fields {
+			sf := &r.fields[i]
+			if sf.tag.Skip || !slices.Contains(sf.tag.Headers(), ft.header) {
+				continue
 			}
+			found = true
+			if prev := sf.transform; prev != nil {
+				sf.transform = func(s string) string { return ft.transform(prev(s)) }
+			} else {
+				sf.transform = ft.transform
+			}
+		}
+		if !found {
+			return fmt.Errorf("transform of header %q: %w", ft.header, ErrUnknownField)
 		}
 	}
 	return nil
 }
 
+// fieldPlan describes how to store a record field to a struct field.
+type fieldPlan struct {
+	column int // Record field index, or missingColumn
+	field  int // Struct field index
+	set    setter
+}
+
+// missingColumn is the record field index of a struct field with a
+// default value that is not mapped to any record field.
+const missingColumn = -1
+
+// noField is the struct field index of the rest field if there is none.
+const noField = -1
+
+// mapField maps the record field column to the struct field sfIndex,
+// in addition to the struct fields it is already mapped to, growing
+// fieldIndex if needed.
+func (r *Reader[T]) mapField(column, sfIndex int) {
+	for len(r.fieldIndex) <= column {
+		r.fieldIndex = append(r.fieldIndex, nil)
+	}
+	r.fieldIndex[column] = append(r.fieldIndex[column], sfIndex)
+}
+
+// compile compiles fieldIndex into a plan to assign record fields to
+// struct fields, so that assignFields does not need to look up the
+// mapping or the type of struct fields for every record.
+// It should be called every time fieldIndex is changed.
+func (r *Reader[T]) compile() {
+	r.plan = r.plan[:0]
+	mapped := make([]bool, len(r.fields))
+	for column, sfIndices := range r.fieldIndex {
+		for _, sfIndex := range sfIndices {
+			r.plan = append(r.plan, fieldPlan{column: column, field: sfIndex, set: r.fields[sfIndex].set})
+			mapped[sfIndex] = true
+		}
+	}
+	for i, sf := range r.fields {
+		if !mapped[i] && !sf.tag.Skip && !sf.tag.Rest && sf.tag.Default != "" {
+			r.plan = append(r.plan, fieldPlan{column: missingColumn, field: i, set: sf.set})
+		}
+	}
+	slices.SortStableFunc(r.plan, func(a, b fieldPlan) int { return a.column - b.column })
+}
+
 // parseHeader parses the header row of the CSV and prepares to store
 // record fields to variables of type T.
-func (r *Reader[T]) parseHeader(header []string, rowPtr T) error {
+func (r *Reader[T]) parseHeader(header []string) error {
+	fieldIndex, err := r.matchHeader(header)
+	if err != nil {
+		return err
+	}
+	r.header = append([]string(nil), header...)
+	r.columns = len(header)
+	r.fieldIndex = fieldIndex
+	r.compile()
+	return nil
+}
+
+// ValidateHeader checks that the header values are compatible with the
+// struct fields of T and the options of the Reader, in the same way as
+// the header read by Read, but without reading from the underlying CSV
+// reader or changing the state of the Reader. It is useful to check a
+// header before reading the file, for example by peeking its first line.
+func (r *Reader[T]) ValidateHeader(header []string) error {
+	_, err := r.matchHeader(header)
+	return err
+}
+
+// matchHeader matches the header values to the struct fields of T,
+// and returns the struct field indices of each record field. A record
+// field is mapped to every struct field with its header value. Trailing
+// "\r" of header values are ignored.
+//
+// If the header has the same value more than once, the value matches
+// its first occurrence, and the nth occurrence is also matched by the
+// value followed by "#n", e.g. "value#2" for the second "value", unless
+// the header has that value itself. With WithCaseInsensitiveHeaders or
+// WithHeaderNormalizer, the same value more than once is an error.
+func (r *Reader[T]) matchHeader(header []string) ([][]int, error) {
 	headerToIndex := make(map[string]int)
+	occurrences := make(map[string][]int) // Record field indices of each header value
 	for i, field := range header {
-		headerToIndex[field] = i
+		// encoding/csv removes "\r" before "\n", but not the extra "\r"
+		// of "\r\r\n", e.g. of a file converted to CRLF twice.
+		field = strings.TrimRight(field, "\r")
+		if r.opts.trimSpace {
+			field = strings.TrimSpace(field)
+		}
+		if r.opts.unquoteHeaders && len(field) >= 2 && field[0] == '"' && field[len(field)-1] == '"' {
+			field = field[1 : len(field)-1]
+		}
+		field = r.opts.headerKey(field)
+		if field == "" {
+			continue // Empty header values are never mapped.
+		}
+		if r.opts.caseInsensitive || r.opts.headerNormalizer != nil {
+			if j, exists := headerToIndex[field]; exists {
+				return nil, fmt.Errorf("header %q and %q: %w", header[j], header[i], ErrDuplicateHeader)
+			}
+		}
+		if _, exists := headerToIndex[field]; !exists {
+			headerToIndex[field] = i
+		}
+		occurrences[field] = append(occurrences[field], i)
 	}
-	rowStruct := reflect.Indirect(reflect.ValueOf(rowPtr))
-	for i := 0; i < rowStruct.NumField(); i++ {
-		f := rowStruct.Type().Field(i)
-		tag := ParseTag(f.Tag.Get("csv"))
-		if r.fieldIndex == nil {
-			r.fieldIndex = make(map[int]int)
+	for field, indices := range occurrences {
+		if len(indices) < 2 {
+			continue
 		}
-		if _, exists := headerToIndex[tag.FieldHeader]; !exists {
+		for n, i := range indices {
+			key := field + "#" + strconv.Itoa(n+1)
+			if _, exists := headerToIndex[key]; !exists {
+				headerToIndex[key] = i
+			}
+		}
+	}
+	fieldIndex := make([][]int, len(header))
+	var fallbacks []int // Struct fields to map by index
+	for i, sf := range r.fields {
+		if sf.tag.Skip || sf.tag.Rest {
+			continue
+		}
+		column, matched := -1, ""
+		for _, fieldHeader := range sf.tag.Headers() {
+			index, exists := headerToIndex[r.opts.headerKey(fieldHeader)]
+			if !exists {
+				continue
+			}
+			if column >= 0 {
+				return nil, fmt.Errorf("field %s: header %q and %q: %w", sf.name, matched, fieldHeader, ErrAmbiguousHeader)
+			}
+			column, matched = index, fieldHeader
+		}
+		if column < 0 {
+			if sf.tag.HasIndex {
+				fallbacks = append(fallbacks, i)
+				continue
+			}
+			if sf.tag.Required {
+				return nil, fmt.Errorf("column %q for field %s: %w", sf.tag.FieldHeader, sf.name, ErrMissingRequiredColumn)
+			}
 			// Tag specifies a field that isn't in the header, all
 			// records will use zero value for that struct field.
 			continue
 		}
-		r.fieldIndex[headerToIndex[tag.FieldHeader]] = i
+		fieldIndex[column] = append(fieldIndex[column], i)
+	}
+	// Header values take precedence, so fields not found in the header
+	// fall back to their index after all header values are matched.
+	for _, i := range fallbacks {
+		sf := r.fields[i]
+		column := sf.tag.Index
+		if column >= len(header) {
+			if sf.tag.Required {
+				return nil, fmt.Errorf("column %q (index %d) for field %s: %w", sf.tag.FieldHeader, column, sf.name, ErrMissingRequiredColumn)
+			}
+			continue
+		}
+		if sfIndices := fieldIndex[column]; len(sfIndices) > 0 {
+			return nil, fmt.Errorf("invalid field %s: record field %d already mapped to field %s: %w", sf.name, column, r.fields[sfIndices[0]].name, ErrDuplicateIndex)
+		}
+		fieldIndex[column] = []int{i}
+	}
+	if r.opts.disallowUnknownColumns {
+		var unknown []string
+		for i, sfIndices := range fieldIndex {
+			if len(sfIndices) == 0 && strings.TrimSpace(header[i]) != "" {
+				unknown = append(unknown, header[i])
+			}
+		}
+		if len(unknown) > 0 {
+			return nil, fmt.Errorf("columns %q: %w", unknown, ErrUnknownColumn)
+		}
+	}
+	return fieldIndex, nil
+}
+
+// Header returns the header values in the order of the file.
+// It returns error if the header has not been read by Read.
+// For files without header, it returns a nil header.
+//
+// Read returns io.EOF both for an empty file and for a file with only
+// a header. Header distinguishes them after io.EOF: it returns
+// ErrHeaderNotRead for an empty file, and the header otherwise.
+func (r *Reader[T]) Header() ([]string, error) {
+	if !r.parsedHeader {
+		return nil, ErrHeaderNotRead
+	}
+	return r.header, nil
+}
+
+// UnmatchedFields returns the names of the tagged struct fields that
+// are not mapped to any record field by the header, in the order of the
+// struct fields, e.g. to warn about all the columns missing from a
+// file at once. Unlike the required tag option, unmatched struct fields
+// are not an error when the header is read: they are left unchanged by
+// Read, or set to the value of the default= tag option. It returns
+// ErrHeaderNotRead if the header has not been read by Read.
+func (r *Reader[T]) UnmatchedFields() ([]string, error) {
+	if !r.parsedHeader {
+		return nil, ErrHeaderNotRead
+	}
+	mapped := make([]bool, len(r.fields))
+	for _, sfIndices := range r.fieldIndex {
+		for _, sfIndex := range sfIndices {
+			mapped[sfIndex] = true
+		}
+	}
+	var names []string
+	for i, sf := range r.fields {
+		if !mapped[i] && !sf.tag.Skip && !sf.tag.Rest && (sf.tag.FieldHeader != "" || sf.tag.HasIndex) {
+			names = append(names, sf.name)
+		}
+	}
+	return names, nil
+}
+
+// indexFields prepares to store record fields of a file without
+// header to variables of type T, using the index= tag option or the
+// position of the struct field among the tagged struct fields. Struct
+// fields without the index= tag option share the record field of the
+// first struct field with the same header value.
+func (r *Reader[T]) indexFields() error {
+	r.fieldIndex = r.fieldIndex[:0]
+	position := 0
+	shared := make(map[string]int) // Record field index of each header value
+	for i, sf := range r.fields {
+		if (sf.tag.FieldHeader == "" && !sf.tag.HasIndex) || sf.tag.Rest {
+			continue
+		}
+		index, fanOut := shared[sf.tag.FieldHeader]
+		switch {
+		case sf.tag.HasIndex:
+			index, fanOut = sf.tag.Index, false
+			position++
+		case !fanOut:
+			index = position
+			position++
+		}
+		if sf.tag.FieldHeader != "" {
+			if _, exists := shared[sf.tag.FieldHeader]; !exists {
+				shared[sf.tag.FieldHeader] = index
+			}
+		}
+		if !fanOut && index < len(r.fieldIndex) && len(r.fieldIndex[index]) > 0 {
+			sfIndex := r.fieldIndex[index][0]
+			return fmt.Errorf("invalid field %s: record field %d already mapped to field %s: %w", sf.name, index, r.fields[sfIndex].name, ErrDuplicateIndex)
+		}
+		r.mapField(index, i)
 	}
+	r.compile()
 	return nil
 }
 
 // assignFields takes a record and assigns to rowPtr struct.
+// Record field values are converted to the type of the struct field,
+// and it returns a *RecordError if a value cannot be converted.
+//
+// The default value of a struct field is used if the record field
+// value is empty or missing. A non-empty record field value always
+// takes precedence over the default value.
+//
+// The record fields beyond the header, or beyond the last mapped record
+// field for files without header, are stored in the rest field if any.
+//
+// The struct fields set from non-empty record field values are recorded
+// for Present.
+//
+// Only the record fields in the plan are visited, so record fields not
+// mapped to any struct field cost nothing, even in wide files.
 func (r *Reader[T]) assignFields(record []string, rowPtr T) error {
-	for i, field := range record {
-		sfIndex, exists := r.fieldIndex[i]
-		if !exists {
+	rowStruct, err := settableRow(rowPtr)
+	if err != nil {
+		return err
+	}
+	if r.present == nil {
+		r.present = make([]bool, len(r.fields))
+	}
+	clear(r.present)
+	for _, p := range r.plan {
+		sf := &r.fields[p.field]
+		var field string
+		var null, fromRecord bool
+		switch {
+		case p.column == missingColumn:
+			// Not in the header, use the default value.
+		case p.column < len(record):
+			field = record[p.column]
+			if r.opts.trimSpace {
+				field = strings.TrimSpace(field)
+			}
+			if r.opts.isNull(field) {
+				field, null = "", true
+			}
+			if r.opts.newlines != nil {
+				field = r.opts.newlines.Replace(field)
+			}
+			if sf.transform != nil {
+				field = sf.transform(field)
+			}
+			if sf.number && r.numbers != nil {
+				field = r.numbers.Replace(field)
+			}
+			fromRecord = field != ""
+		case sf.tag.Default == "":
+			// The record is too short, keep the struct field unchanged.
 			continue
 		}
-		rowStruct := reflect.Indirect(reflect.ValueOf(rowPtr))
-		rowStruct.FieldByIndex([]int{sfIndex}).SetString(field)
+		if field == "" {
+			if sf.tag.Default != "" {
+				field = sf.tag.Default
+				if r.opts.logger != nil {
+					r.opts.logger(Event{Kind: EventDefaulted, Line: r.Line(), Field: sf.name})
+				}
+			} else if sf.tag.OmitEmpty {
+				// Keep the existing value of the struct field.
+				continue
+			} else if null {
+				fieldByIndex(rowStruct, sf.index).SetZero()
+				if r.opts.logger != nil {
+					r.opts.logger(Event{Kind: EventNull, Line: r.Line(), Field: sf.name})
+				}
+				continue
+			}
+		}
+		if err := p.set(fieldByIndex(rowStruct, sf.index), field); err != nil {
+			return r.recordError(record, p.column, sf.name, fmt.Errorf("%w %q: %w", ErrConversion, field, err))
+		}
+		r.present[p.field] = fromRecord
+	}
+	if r.rest != noField {
+		rest := fieldByIndex(rowStruct, r.fields[r.rest].index)
+		rest.SetZero()
+		if len(record) > len(r.fieldIndex) {
+			rest.Set(reflect.ValueOf(slices.Clone(record[len(r.fieldIndex):])).Convert(rest.Type()))
+			r.present[r.rest] = true
+		}
 	}
 	return nil
 }
 
+// settableRow returns the struct rowPtr points to. It returns error
+// instead of panicking if rowPtr is not a non-nil pointer to a struct,
+// such as when a Reader is not created by NewReader.
+func settableRow(rowPtr any) (reflect.Value, error) {
+	rowValue := reflect.ValueOf(rowPtr)
+	if rowValue.Kind() != reflect.Pointer {
+		return reflect.Value{}, ErrNotPointer
+	}
+	if rowValue.IsNil() {
+		return reflect.Value{}, ErrNilRow
+	}
+	rowStruct := rowValue.Elem()
+	if rowStruct.Kind() != reflect.Struct {
+		return reflect.Value{}, ErrNotStructPointer
+	}
+	return rowStruct, nil
+}
+
+// recordError returns a *RecordError for the record field at index i
+// of record, the last record read. The position is only known if the
+// record has the record field, e.g. not for a default value used for a
+// short record.
+func (r *Reader[T]) recordError(record []string, i int, fieldName string, err error) *RecordError {
+	recErr := &RecordError{Record: r.records, Field: fieldName, Err: err}
+	if r.rd != nil && r.records > 0 && i >= 0 && i < len(record) {
+		recErr.Line, recErr.Column = r.rd.FieldPos(i)
+	}
+	return recErr
+}
+
+// Line returns the line number in the file where the last record read
+// starts, counting the header and lines skipped by WithSkipLines, or 0
+// if no record has been read. It is useful to report progress between
+// calls to Read. After a *ReadError of a malformed record, it is still
+// the line of the last record read without error.
+func (r *Reader[T]) Line() int {
+	return r.line
+}
+
+// Extras returns the record field values of the last record read that
+// are not mapped to any struct field, in the order of the file, or nil
+// if there is none. These are the columns of header values unknown to
+// T, and the record fields beyond the header if T has no rest field.
+// It is useful to log the columns ignored by Read.
+func (r *Reader[T]) Extras() []string {
+	var extras []string
+	for i, field := range r.last {
+		if (i < len(r.fieldIndex) && len(r.fieldIndex[i]) == 0) || (i >= len(r.fieldIndex) && r.rest == noField) {
+			extras = append(extras, field)
+		}
+	}
+	return extras
+}
+
+// Present returns the names of the struct fields set from the record
+// field values of the last record read, in the order of the struct
+// fields, e.g. to update only the columns of a partial file. A struct
+// field is not present if its record field value is empty, or null by
+// WithNullValues, even if it is set to the default value of the
+// default= tag option or to its zero value, or if the header or the
+// record has no record field for it. The rest field is present if the
+// record has record fields beyond the header.
+func (r *Reader[T]) Present() []string {
+	var names []string
+	for i, present := range r.present {
+		if present {
+			names = append(names, r.fields[i].name)
+		}
+	}
+	return names
+}
+
 // Read reads one record as rowPtr.
 // It returns io.EOF if there's no more record to read.
+//
+// With WithRecordErrorHandler, the records that cannot be stored are
+// passed to the handler, and rowPtr is restored to its value before
+// the record if the handler skips it.
 func (r *Reader[T]) Read(rowPtr T) error {
-	if !r.parsedHeader {
-		rcd, err := r.rd.Read()
+	// Check rowPtr before reading so that the record is not lost.
+	if _, err := settableRow(rowPtr); err != nil {
+		return err
+	}
+	if r.opts.recordErrorHandler == nil {
+		rcd, err := r.next()
 		if err != nil {
 			return err
 		}
-		if err := r.parseHeader(rcd, rowPtr); err != nil {
+		return r.store(rcd, rowPtr)
+	}
+	rowStruct := reflect.ValueOf(rowPtr).Elem()
+	if !r.saved.IsValid() {
+		r.saved = reflect.New(rowStruct.Type()).Elem()
+	}
+	r.saved.Set(rowStruct)
+	for {
+		rcd, err := r.next()
+		if err != nil {
+			return err
+		}
+		err = r.store(rcd, rowPtr)
+		if err == nil {
+			return nil
+		}
+		// Undo the struct fields assigned before the error.
+		rowStruct.Set(r.saved)
+		if err := r.opts.recordErrorHandler(r.Line(), rcd, err); err != nil {
+			return err
+		}
+		if r.opts.logger != nil {
+			r.opts.logger(Event{Kind: EventSkipped, Line: r.Line(), Err: err})
+		}
+	}
+}
+
+// ReadValue reads one record of r and returns it as a struct value,
+// for callers that prefer not to declare a variable for Read. It is a
+// function rather than a method of Reader because methods cannot have
+// type parameters, so T is the struct type, not the pointer type. It
+// returns the zero value of T with the errors of Read, including
+// io.EOF if there's no more record to read.
+func ReadValue[T any](r *Reader[*T]) (T, error) {
+	var row T
+	if err := r.Read(&row); err != nil {
+		var zero T
+		return zero, err
+	}
+	return row, nil
+}
+
+// Peek reads the next record as a newly allocated T without consuming
+// it, so the next Read, Skip or All returns the same record. Only one
+// record of lookahead is supported, calling Peek again returns the same
+// record. It returns io.EOF if there's no more record to read. Errors
+// of storing the record are returned without calling the handler of
+// WithRecordErrorHandler, which is called by the next Read.
+func (r *Reader[T]) Peek() (T, error) {
+	buffered := r.peeked != nil
+	rcd, err := r.next()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if !buffered {
+		// The underlying CSV reader may reuse the record.
+		rcd = slices.Clone(rcd)
+	}
+	r.peeked = rcd
+	rowPtr := r.newRow()
+	if err := r.store(rcd, rowPtr); err != nil {
+		var zero T
+		return zero, err
+	}
+	return rowPtr, nil
+}
+
+// next returns the record buffered by Peek if any, or reads the next
+// data record, moving on to the next file of NewMultiReader at the end
+// of a file.
+func (r *Reader[T]) next() ([]string, error) {
+	if r.peeked != nil {
+		rcd := r.peeked
+		r.peeked = nil
+		return rcd, nil
+	}
+	rcd, err := r.readData()
+	for err == io.EOF && len(r.pending) > 0 {
+		pending := r.pending[1:]
+		r.Reset(r.pending[0])
+		r.pending = pending
+		rcd, err = r.readData()
+	}
+	return rcd, err
+}
+
+// store checks the record and stores it in rowPtr.
+func (r *Reader[T]) store(rcd []string, rowPtr T) error {
+	r.last = rcd
+	if r.opts.maxRecordBytes > 0 {
+		if err := r.checkSize(rcd); err != nil {
+			return err
+		}
+	}
+	if r.opts.strictColumns {
+		if err := r.checkColumns(rcd); err != nil {
 			return err
 		}
+	}
+	return r.assignFields(rcd, rowPtr)
+}
+
+// Skip reads the next record and discards it without storing it in a
+// row, reading the header first if needed. It is cheaper than Read to
+// skip records that are not needed. It returns io.EOF if there's no
+// more record to read.
+func (r *Reader[T]) Skip() error {
+	_, err := r.next()
+	return err
+}
+
+// readData reads the next data record, skipping the lines before the
+// header and reading the header first if they have not been read.
+func (r *Reader[T]) readData() ([]string, error) {
+	if !r.skippedLines {
+		if err := r.skipLines(); err != nil {
+			return nil, err
+		}
+		r.skippedLines = true
+	}
+	if !r.parsedHeader {
+		rcd, err := r.readRecord()
+		if err != nil {
+			return nil, r.headerLineError(err)
+		}
+		if err := r.parseHeader(rcd); err != nil {
+			return nil, err
+		}
 		r.parsedHeader = true
+		if r.opts.logger != nil {
+			r.logUnmatched()
+		}
+	}
+	return r.readRecord()
+}
+
+// logUnmatched reports the struct fields not mapped by the header just
+// read to the logger of WithLogger.
+func (r *Reader[T]) logUnmatched() {
+	unmatched, _ := r.UnmatchedFields()
+	line, _ := r.rd.FieldPos(0)
+	for _, name := range unmatched {
+		r.opts.logger(Event{Kind: EventUnmatched, Line: line, Field: name})
 	}
+}
+
+// skipLines discards the records to skip at the start of the file.
+// The discarded records are allowed to have any number of record fields.
+func (r *Reader[T]) skipLines() error {
+	n := r.opts.skipLines
+	if r.hasHeaderLine() {
+		n = r.opts.headerLine - 1
+	}
+	if n <= 0 {
+		return nil
+	}
+	fieldsPerRecord := r.rd.FieldsPerRecord
+	r.rd.FieldsPerRecord = -1
+	defer func() { r.rd.FieldsPerRecord = fieldsPerRecord }()
+	for i := 0; i < n; i++ {
+		if _, err := r.readRecord(); err != nil {
+			return r.headerLineError(err)
+		}
+	}
+	return nil
+}
+
+// hasHeaderLine reports whether the position of the header is set by
+// WithHeaderLine.
+func (r *Reader[T]) hasHeaderLine() bool {
+	return r.opts.headerLine > 0 && !r.opts.noHeader
+}
+
+// headerLineError returns an error for err at or before the header set
+// by WithHeaderLine, which must exist, so io.EOF is reported as error.
+func (r *Reader[T]) headerLineError(err error) error {
+	if err == io.EOF && r.hasHeaderLine() {
+		return fmt.Errorf("header line %d: %w", r.opts.headerLine, ErrHeaderLineNotFound)
+	}
+	return err
+}
+
+// bom is the UTF-8 byte order mark, which some programs such as Excel
+// write at the start of a CSV file.
+const bom = "\uFEFF"
+
+// readRecord reads a record with the underlying CSV reader, removing
+// the UTF-8 byte order mark at the start of the file if any, and
+// skipping the comments of WithCommentPrefix after the header.
+// Errors other than io.EOF are returned as a *ReadError.
+func (r *Reader[T]) readRecord() ([]string, error) {
+	fieldsPerRecord := r.rd.FieldsPerRecord
 	rcd, err := r.rd.Read()
+	if rcd != nil {
+		r.records++
+	}
+	// Comments are skipped even with the wrong number of record fields,
+	// and they do not set the number of record fields of the file.
+	for r.skippedLines && r.parsedHeader && r.opts.isComment(rcd) {
+		r.rd.FieldsPerRecord = fieldsPerRecord
+		if rcd, err = r.rd.Read(); rcd != nil {
+			r.records++
+		}
+	}
 	if err != nil {
-		return err
+		if err == io.EOF {
+			return nil, err
+		}
+		readErr := &ReadError{Record: r.records, Err: err}
+		if rcd == nil {
+			readErr.Record++ // The record could not be read.
+		}
+		return nil, readErr
 	}
-	if err := r.assignFields(rcd, rowPtr); err != nil {
-		return err
+	if r.records == 1 && len(rcd) > 0 {
+		rcd[0] = strings.TrimPrefix(rcd[0], bom)
+	}
+	// The underlying CSV reader only has the positions of the record
+	// fields of records read without error.
+	r.line, _ = r.rd.FieldPos(0)
+	return rcd, nil
+}
+
+// checkColumns checks that the record has the expected number of record
+// fields. For files without header, the first record sets the expected
+// number of record fields.
+func (r *Reader[T]) checkColumns(record []string) error {
+	if r.columns == 0 {
+		r.columns = len(record)
+	}
+	if len(record) != r.columns {
+		line, _ := r.rd.FieldPos(0)
+		return &ColumnCountError{Record: r.records, Line: line, Expected: r.columns, Actual: len(record)}
+	}
+	return nil
+}
+
+// checkSize checks that the record field values of the record are not
+// more than the bytes of WithMaxRecordBytes in total.
+func (r *Reader[T]) checkSize(record []string) error {
+	size := 0
+	for _, field := range record {
+		size += len(field)
+	}
+	if size > r.opts.maxRecordBytes {
+		line, _ := r.rd.FieldPos(0)
+		return &RecordSizeError{Record: r.records, Line: line, Size: size, Max: r.opts.maxRecordBytes}
 	}
 	return nil
 }
+
+// newRow allocates a new zero value of the struct pointed to by T.
+func (r *Reader[T]) newRow() T {
+	var rowPtr T
+	return reflect.New(reflect.TypeOf(rowPtr).Elem()).Interface().(T)
+}
+
+// ReadAll reads all the remaining records, each stored in a newly
+// allocated T. A successful call returns err == nil, not err == io.EOF.
+// If an error occurs, ReadAll returns the records read so far along
+// with the error.
+func (r *Reader[T]) ReadAll() ([]T, error) {
+	var rows []T
+	for {
+		rowPtr := r.newRow()
+		if err := r.Read(rowPtr); err != nil {
+			if err == io.EOF {
+				return rows, nil
+			}
+			return rows, err
+		}
+		rows = append(rows, rowPtr)
+	}
+}
+
+// ReadInto reads up to len(dst) records into dst, and returns the
+// number of records read. At the end of the file, it returns the number
+// of records read with io.EOF.
+//
+// As T is a pointer, each non-nil element of dst is reused to store a
+// record like Read, so struct fields not assigned by the record keep
+// their values; each nil element is set to a newly allocated T once
+// its record is read. Nil elements of dst after the records read stay
+// nil, and at the end of the file the other elements are unchanged.
+func (r *Reader[T]) ReadInto(dst []T) (int, error) {
+	for i := range dst {
+		row := dst[i]
+		if reflect.ValueOf(row).IsNil() {
+			row = r.newRow()
+		}
+		if err := r.Read(row); err != nil {
+			return i, err
+		}
+		dst[i] = row
+	}
+	return len(dst), nil
+}
+
+// ReadBatch reads up to cap(dst) records into the elements of
+// dst[:cap(dst)], and returns dst resliced to the records read. At the
+// end of the file, it returns the records read with io.EOF. It is a
+// variant of ReadInto for reusing the same rows for every batch, e.g.
+// with a sync.Pool of slices, to avoid allocating rows for every record.
+//
+// Each non-nil element of dst[:cap(dst)] is reset to the zero value of
+// the struct it points to before the record is stored, so no value of
+// a previous record is kept; each nil element is set to a newly
+// allocated T once its record is read. The elements after the records
+// read are unchanged. The returned rows alias dst: the next ReadBatch with the
+// same backing array overwrites them, so rows that are retained must be
+// copied, and a slice must not be returned to a pool while its rows are
+// in use. The record field values of string fields are not shared
+// between records, so they can be retained.
+func (r *Reader[T]) ReadBatch(dst []T) ([]T, error) {
+	dst = dst[:cap(dst)]
+	var saved reflect.Value // Value of the reused row before the record
+	for i := range dst {
+		row := reflect.ValueOf(dst[i])
+		if row.IsNil() {
+			rowPtr := r.newRow()
+			if err := r.Read(rowPtr); err != nil {
+				return dst[:i], err
+			}
+			dst[i] = rowPtr
+			continue
+		}
+		if !saved.IsValid() {
+			saved = reflect.New(row.Type().Elem()).Elem()
+		}
+		saved.Set(row.Elem())
+		row.Elem().SetZero()
+		if err := r.Read(dst[i]); err != nil {
+			row.Elem().Set(saved)
+			return dst[:i], err
+		}
+	}
+	return dst, nil
+}
+
+// All returns an iterator over the remaining records, each stored in a
+// newly allocated T. The iteration stops at io.EOF, which is not
+// yielded. Any other error is yielded with a nil T and ends the
+// iteration.
+func (r *Reader[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			rowPtr := r.newRow()
+			if err := r.Read(rowPtr); err != nil {
+				if err != io.EOF {
+					var zero T
+					yield(zero, err)
+				}
+				return
+			}
+			if !yield(rowPtr, nil) {
+				return
+			}
+		}
+	}
+}
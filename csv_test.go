@@ -1,13 +1,21 @@
 package csv
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	_ "embed"
 )
@@ -15,6 +23,23 @@ import (
 //go:embed testdata/example.csv
 var exampleCSV string
 
+// listType is a comma-separated list of values in a record field.
+type listType []string
+
+var errEmptyList = fmt.Errorf("empty list")
+
+func (l *listType) UnmarshalCSV(s string) error {
+	if s == "" {
+		return errEmptyList
+	}
+	*l = strings.Split(s, ",")
+	return nil
+}
+
+func (l listType) MarshalCSV() (string, error) {
+	return strings.Join(l, ","), nil
+}
+
 // exampleType corresponds to exampleCSV
 type exampleType struct {
 	Bar string `csv:"bar"`
@@ -25,16 +50,60 @@ type exampleType struct {
 func TestReader_validateFieldsType(t *testing.T) {
 	// not assignable
 	r := &Reader[exampleType]{}
-	if want, got := errNotPointer, r.validateFields(); !errors.Is(got, want) {
+	if want, got := ErrNotPointer, r.validateFields(); !errors.Is(got, want) {
 		t.Fatalf("expected error %v but got %v", want, got)
 	}
 	// wrong type
 	r2 := &Reader[*struct {
-		Field int `csv:"field"`
+		Field chan int `csv:"field"`
+	}]{}
+	if want, got := ErrFieldNotAssignable, r2.validateFields(); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestReader_validateFieldsAll(t *testing.T) {
+	r := &Reader[*struct {
+		Foo  chan int `csv:"foo"`
+		Bar  string   `csv:"bar,index=-1"`
+		Baz  string   `csv:"baz,omitempy"`
+		Foo2 string   `csv:"foo"`
+		Qux  int      `csv:"qux,default=x"`
 	}]{}
-	if want, got := errFieldNotAssignable, r2.validateFields(); !errors.Is(got, want) {
+	err := r.validateFields()
+	for _, want := range []error{ErrFieldNotAssignable, ErrInvalidIndex, ErrUnknownOption, strconv.ErrSyntax} {
+		if !errors.Is(err, want) {
+			t.Fatalf("expected error %v in %v", want, err)
+		}
+	}
+}
+
+// A tagged unexported field is an error instead of a panic on Read.
+func TestReader_validateFieldsUnexported(t *testing.T) {
+	_, err := NewReader[*struct {
+		Foo string `csv:"foo"`
+		bar string `csv:"bar"`
+		baz string // Untagged unexported fields are ignored.
+	}](csv.NewReader(strings.NewReader(exampleCSV)))
+	if want, got := ErrUnexportedField, err; !errors.Is(got, want) {
 		t.Fatalf("expected error %v but got %v", want, got)
 	}
+
+	type untagged struct {
+		Foo string `csv:"foo"`
+		bar string
+	}
+	r, err := NewReader[*untagged](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record untagged
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (untagged{Foo: "1"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
 }
 
 func TestReader_parseHeader(t *testing.T) {
@@ -47,8 +116,7 @@ func TestReader_parseHeader(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected no error for reading header but got %v", err)
 	}
-	var record exampleType
-	if err := r.parseHeader(header, &record); err != nil {
+	if err := r.parseHeader(header); err != nil {
 		t.Fatalf("expected no error for parsing header but got %v", err)
 	}
 
@@ -64,16 +132,278 @@ func TestReader_parseHeader(t *testing.T) {
 		{headerIndex: 2, structFieldIndex: 1},
 	}
 	for _, tc := range testCases {
-		if want, got := tc.structFieldIndex, r.fieldIndex[tc.headerIndex]; want != got {
+		if want, got := []int{tc.structFieldIndex}, r.fieldIndex[tc.headerIndex]; !slices.Equal(want, got) {
 			t.Fatalf("expected header index %d → struct field index %d but got struct field index %d", tc.headerIndex, want, got)
 		}
 	}
 }
 
+func TestReader_parseHeaderUnmapped(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader("")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if err := r.parseHeader([]string{"foo", "extra", "bar", "baz", "qux"}); err != nil {
+		t.Fatalf("expected no error for parsing header but got %v", err)
+	}
+	if want, got := [][]int{{2}, nil, {0}, {1}, nil}, r.fieldIndex; !reflect.DeepEqual(want, got) {
+		t.Fatalf("expected field index %v but got %v", want, got)
+	}
+}
+
+func TestReader_unquoteHeaders(t *testing.T) {
+	testCases := [...]struct {
+		name           string
+		data           string
+		opts           []ReaderOption
+		expectedRecord exampleType
+	}{
+		{name: "escaped quotes", data: "\"\"\"foo\"\"\",bar,\"\"\"baz\"\"\"\n1,2,hello\n", expectedRecord: exampleType{Foo: "1", Bar: "2", Baz: "hello"}},
+		{name: "lazy quotes", data: "foo, \"bar\", \"baz\"\n1,2,hello\n", opts: []ReaderOption{WithLazyQuotes(true), WithTrimSpace()}, expectedRecord: exampleType{Foo: "1", Bar: "2", Baz: "hello"}},
+		{name: "single quote", data: "foo\",bar\n1,2\n", opts: []ReaderOption{WithLazyQuotes(true)}, expectedRecord: exampleType{Bar: "2"}},
+		{name: "record field values", data: "foo,bar,baz\n\"\"\"1\"\"\",2,hello\n", expectedRecord: exampleType{Foo: "\"1\"", Bar: "2", Baz: "hello"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(tc.data)), append(tc.opts, WithUnquoteHeaders())...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record exampleType
+			if err := r.Read(&record); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+
+	// Without the option, the quotes are part of the header values.
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader("\"\"\"foo\"\"\",bar\n1,2\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Bar: "2"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_UnmatchedFields(t *testing.T) {
+	type unmatchedType struct {
+		Foo      string `csv:"foo"`
+		Bar      string `csv:"bar|qux"`
+		Missing  string `csv:"missing"`
+		Country  string `csv:"country,default=US"`
+		Index    string `csv:",index=2"`
+		Skipped  string `csv:"-"`
+		Untagged string
+	}
+	r, err := NewReader[*unmatchedType](csv.NewReader(strings.NewReader("foo,qux,baz\n1,2,3\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if _, err := r.UnmatchedFields(); !errors.Is(err, ErrHeaderNotRead) {
+		t.Fatalf("expected error %v but got %v", ErrHeaderNotRead, err)
+	}
+	var record unmatchedType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	unmatched, err := r.UnmatchedFields()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	// Index is mapped by its index as the fallback.
+	if want, got := []string{"Missing", "Country"}, unmatched; !slices.Equal(want, got) {
+		t.Fatalf("expected unmatched fields %q but got %q", want, got)
+	}
+
+	r2, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if err := r2.Read(&exampleType{}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if unmatched, err := r2.UnmatchedFields(); err != nil || unmatched != nil {
+		t.Fatalf("expected no unmatched fields but got %q, %v", unmatched, err)
+	}
+}
+
+func TestReader_ValidateHeader(t *testing.T) {
+	type requiredType struct {
+		Foo string `csv:"foo,required"`
+		Bar string `csv:"bar"`
+	}
+	testCases := [...]struct {
+		name        string
+		header      []string
+		opts        []ReaderOption
+		expectedErr error
+	}{
+		{name: "valid", header: []string{"bar", "foo"}},
+		{name: "missing required", header: []string{"bar"}, expectedErr: ErrMissingRequiredColumn},
+		{name: "duplicate", header: []string{"foo", "FOO"}, opts: []ReaderOption{WithCaseInsensitiveHeaders()}, expectedErr: ErrDuplicateHeader},
+		{name: "unknown", header: []string{"foo", "baz"}, opts: []ReaderOption{WithDisallowUnknownColumns()}, expectedErr: ErrUnknownColumn},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*requiredType](csv.NewReader(strings.NewReader("foo,bar\n1,2\n")), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			if want, got := tc.expectedErr, r.ValidateHeader(tc.header); !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			// The Reader is not changed by ValidateHeader.
+			if _, err := r.Header(); !errors.Is(err, ErrHeaderNotRead) {
+				t.Fatalf("expected error %v but got %v", ErrHeaderNotRead, err)
+			}
+			var record requiredType
+			if err := r.Read(&record); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := (requiredType{Foo: "1", Bar: "2"}), record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_indexFallback(t *testing.T) {
+	type hybridType struct {
+		Name  string `csv:"name,index=0"`
+		Email string `csv:"email,index=2"`
+		Age   int    `csv:",index=1"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		expectedRecord hybridType
+		expectedErr    error
+	}{
+		{name: "header", data: "email,age,name\na@example.com,42,alice\n", expectedRecord: hybridType{Name: "alice", Email: "a@example.com", Age: 42}},
+		{name: "index", data: "Full name,Age,E-mail\nalice,42,a@example.com\n", expectedRecord: hybridType{Name: "alice", Email: "a@example.com", Age: 42}},
+		{name: "mixed", data: "Full name,Age,name\nx,42,alice\n", expectedErr: ErrDuplicateIndex},
+		{name: "index out of range", data: "name,x\nalice,42\n", expectedRecord: hybridType{Name: "alice", Age: 42}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*hybridType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record hybridType
+			if want, got := tc.expectedErr, r.Read(&record); !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+// Empty header values are not mapped, even to struct fields without a
+// header value.
+func TestReader_parseHeaderCRLF(t *testing.T) {
+	testCases := [...]struct {
+		name       string
+		data       string
+		lazyQuotes bool
+	}{
+		{name: "LF", data: "foo,bar,baz\n1,2,hello\n"},
+		{name: "CRLF", data: "foo,bar,baz\r\n1,2,hello\r\n"},
+		{name: "CRLF quoted", data: "foo,bar,\"baz\"\r\n1,2,hello\r\n"},
+		{name: "CRLF lazy quotes", data: "foo,bar,\"baz\"\r\n1,2,hello\r\n", lazyQuotes: true},
+		{name: "CR only at end of file", data: "foo,bar,baz\r"},
+		// encoding/csv keeps the first "\r" of "\r\r\n".
+		{name: "CRCRLF", data: "foo,bar,baz\r\r\n1,2,hello\r\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(tc.data)), WithLazyQuotes(tc.lazyQuotes), WithDisallowUnknownColumns())
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record exampleType
+			if err := r.Read(&record); err != nil && err != io.EOF {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := [][]int{{2}, {0}, {1}}, r.fieldIndex; !reflect.DeepEqual(want, got) {
+				t.Fatalf("expected field index %v but got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_duplicateHeader(t *testing.T) {
+	type valueType struct {
+		First  string `csv:"value"`
+		Second string `csv:"value#2"`
+		Third  string `csv:"value#3"`
+		Name   string `csv:"name#2"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		expectedRecord valueType
+	}{
+		{name: "two", data: "value,name,value\n1,a,2\n", expectedRecord: valueType{First: "1", Second: "2"}},
+		{name: "three", data: "value,value,value\n1,2,3\n", expectedRecord: valueType{First: "1", Second: "2", Third: "3"}},
+		{name: "unique", data: "value,name\n1,a\n", expectedRecord: valueType{First: "1"}},
+		{name: "literal suffix", data: "value,value#2,value\n1,2,3\n", expectedRecord: valueType{First: "1", Second: "2"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*valueType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record valueType
+			if err := r.Read(&record); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_parseHeaderEmpty(t *testing.T) {
+	type emptyType struct {
+		Foo   string `csv:"foo"`
+		Empty string `csv:""`
+		Index string `csv:",omitempty"`
+	}
+	r, err := NewReader[*emptyType](csv.NewReader(strings.NewReader("foo,,\n1,2,3\n")), WithCaseInsensitiveHeaders())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record emptyType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := [][]int{{0}, nil, nil}, r.fieldIndex; !reflect.DeepEqual(want, got) {
+		t.Fatalf("expected field index %v but got %v", want, got)
+	}
+	if want, got := (emptyType{Foo: "1"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
 func TestReader_assignFields(t *testing.T) {
 	// CSV header: foo,bar,baz
 	// Struct fields: Bar Baz Foo
-	r := &Reader[*exampleType]{fieldIndex: map[int]int{0: 2, 1: 0, 2: 1}}
+	r := &Reader[*exampleType]{fieldIndex: [][]int{{2}, {0}, {1}}}
+	r.cacheFields()
+	r.compile()
 
 	testCases := [...]struct {
 		name           string
@@ -120,50 +450,3076 @@ func TestReader(t *testing.T) {
 	}
 }
 
-// If a file has no header, Reader will return the underlying io.EOF error.
-func TestReader_emptyFile(t *testing.T) {
-	emptyFile := ``
-	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(emptyFile)))
+// numericType has numeric fields of various kinds.
+type numericType struct {
+	Age   int     `csv:"age"`
+	Small int8    `csv:"small"`
+	Count uint64  `csv:"count"`
+	Price float64 `csv:"price"`
+	Ratio float32 `csv:"ratio"`
+	Name  string  `csv:"name"`
+}
+
+func TestReader_numeric(t *testing.T) {
+	data := "name,age,small,count,price,ratio\nalice,42,-8,18446744073709551615,9.99,0.5\n"
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
 	if err != nil {
 		t.Fatalf("expected no error for creating reader but got %v", err)
 	}
-	var record exampleType
-	if err := r.Read(&record); err != io.EOF {
-		t.Fatalf("expected EOF error but got none")
+	var record numericType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := numericType{Age: 42, Small: -8, Count: 18446744073709551615, Price: 9.99, Ratio: 0.5, Name: "alice"}
+	if want, got := expected, record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
 	}
 }
 
-// If a file has only header, it's equivalent to reading
-// an empty file with Reader because Reader will process
-// the header then try to read the content.
-func TestReader_headerOnly(t *testing.T) {
-	headerOnly := "foo,bar,baz\n"
-	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(headerOnly)))
+func TestReader_numericInvalid(t *testing.T) {
+	testCases := [...]struct {
+		name string
+		data string
+	}{
+		{name: "not a number", data: "age\nabc\n"},
+		{name: "overflow", data: "small\n128\n"},
+		{name: "negative unsigned", data: "count\n-1\n"},
+		{name: "malformed float", data: "price\n1.2.3\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record numericType
+			err = r.Read(&record)
+			var numErr *strconv.NumError
+			if !errors.As(err, &numErr) {
+				t.Fatalf("expected conversion error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestReader_bool(t *testing.T) {
+	type boolType struct {
+		Default bool `csv:"default"`
+		Custom  bool `csv:"custom,true=yes|y,false=no"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		expectedRecord boolType
+		expectError    bool
+	}{
+		{name: "default literals", data: "default,custom\ntrue,yes\n", expectedRecord: boolType{Default: true, Custom: true}},
+		{name: "numeric default literals", data: "default,custom\n0,y\n", expectedRecord: boolType{Default: false, Custom: true}},
+		{name: "custom false literal", data: "default,custom\n1,no\n", expectedRecord: boolType{Default: true, Custom: false}},
+		{name: "invalid default literal", data: "default,custom\nyes,yes\n", expectError: true},
+		{name: "invalid custom literal", data: "default,custom\ntrue,true\n", expectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*boolType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record boolType
+			err = r.Read(&record)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_time(t *testing.T) {
+	type timeType struct {
+		Created time.Time `csv:"created_at,layout=2006-01-02"`
+		Updated time.Time `csv:"updated_at"`
+	}
+	data := "created_at,updated_at\n2023-05-01,2023-05-02T10:30:00Z\n"
+	r, err := NewReader[*timeType](csv.NewReader(strings.NewReader(data)))
 	if err != nil {
 		t.Fatalf("expected no error for creating reader but got %v", err)
 	}
-	var record exampleType
-	if err := r.Read(&record); err != io.EOF {
-		t.Fatalf("expected EOF error but got none")
+	var record timeType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), record.Created; !want.Equal(got) {
+		t.Fatalf("expecting created_at %v but got %v", want, got)
+	}
+	if want, got := time.Date(2023, 5, 2, 10, 30, 0, 0, time.UTC), record.Updated; !want.Equal(got) {
+		t.Fatalf("expecting updated_at %v but got %v", want, got)
+	}
+
+	// Layout does not match the value
+	r2, err := NewReader[*timeType](csv.NewReader(strings.NewReader("created_at\n01/05/2023\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var parseErr *time.ParseError
+	if err := r2.Read(&record); !errors.As(err, &parseErr) {
+		t.Fatalf("expected time parse error but got %v", err)
+	}
+	if want, got := "2006-01-02", parseErr.Layout; want != got {
+		t.Fatalf("expected error for layout %s but got %s", want, got)
 	}
 }
 
-func ExampleReader() {
+func TestReader_ReadAll(t *testing.T) {
 	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
 	if err != nil {
-		log.Fatal(err)
+		t.Fatalf("expected no error for creating reader but got %v", err)
 	}
-	for {
-		var record exampleType
-		if err := r.Read(&record); err != nil {
-			if err == io.EOF {
-				break
-			}
-			log.Fatal(err)
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}
+	if want, got := len(expected), len(rows); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range expected {
+		if want, got := expected[i], *rows[i]; want != got {
+			t.Fatalf("expecting record %d to be %v but got %v", i, want, got)
+		}
+	}
+	if rows[0] == rows[1] {
+		t.Fatalf("expected records to be separately allocated")
+	}
+}
+
+func TestReader_ReadAllError(t *testing.T) {
+	data := "name,age\nalice,1\nbob,x\ncarol,3\n"
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows, err := r.ReadAll()
+	if err == nil {
+		t.Fatalf("expected error but got none")
+	}
+	if want, got := 1, len(rows); want != got {
+		t.Fatalf("expected %d records read before error but got %d", want, got)
+	}
+	if want, got := "alice", rows[0].Name; want != got {
+		t.Fatalf("expected first record name %s but got %s", want, got)
+	}
+}
+
+func TestReader_All(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var rows []*exampleType
+	for row, err := range r.All() {
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		rows = append(rows, row)
+	}
+	expected := []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}
+	if want, got := len(expected), len(rows); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range expected {
+		if want, got := expected[i], *rows[i]; want != got {
+			t.Fatalf("expecting record %d to be %v but got %v", i, want, got)
+		}
+	}
+}
+
+func TestReader_AllBreak(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	for row, err := range r.All() {
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if want, got := (exampleType{Foo: "1", Bar: "2", Baz: "hello"}), *row; want != got {
+			t.Fatalf("expecting %v but got %v", want, got)
+		}
+		break
+	}
+	// The second record is still available after breaking out of the loop
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "3", Bar: "2", Baz: "world"}), record; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+}
+
+func TestReader_AllError(t *testing.T) {
+	data := "name,age\nalice,1\nbob,x\ncarol,3\n"
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var (
+		count   int
+		lastErr error
+	)
+	for _, err := range r.All() {
+		count++
+		lastErr = err
+	}
+	if want, got := 2, count; want != got {
+		t.Fatalf("expected %d iterations but got %d", want, got)
+	}
+	if lastErr == nil {
+		t.Fatalf("expected error but got none")
+	}
+}
+
+func TestReader_withoutHeader(t *testing.T) {
+	type indexType struct {
+		Foo string `csv:"foo"`
+		Bar int    `csv:"bar,index=3"`
+		Baz string `csv:"baz"`
+		Qux string `csv:",index=1"`
+	}
+	data := "1,a,hello,2\n3,b,world,4\n"
+	r, err := NewReader[*indexType](csv.NewReader(strings.NewReader(data)), WithoutHeader())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []indexType{{Foo: "1", Bar: 2, Baz: "hello", Qux: "a"}, {Foo: "3", Bar: 4, Baz: "world", Qux: "b"}}
+	if want, got := len(expected), len(rows); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range expected {
+		if want, got := expected[i], *rows[i]; want != got {
+			t.Fatalf("expecting record %d to be %+v but got %+v", i, want, got)
+		}
+	}
+}
+
+func TestReader_withoutHeaderDuplicateIndex(t *testing.T) {
+	type duplicateType struct {
+		Foo string `csv:"foo"`
+		Bar string `csv:"bar,index=0"`
+	}
+	_, err := NewReader[*duplicateType](csv.NewReader(strings.NewReader("")), WithoutHeader())
+	if want, got := ErrDuplicateIndex, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestReader_caseInsensitiveHeaders(t *testing.T) {
+	data := "Foo,BAR,baz\nOne,Two,Three\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)), WithCaseInsensitiveHeaders())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "One", Bar: "Two", Baz: "Three"}), record; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+
+	// Without the option, headers are matched exactly
+	r2, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record2 exampleType
+	if err := r2.Read(&record2); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Baz: "Three"}), record2; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+}
+
+// stripAccents replaces the accented letters used in the tests with
+// the letters without accent.
+var stripAccents = strings.NewReplacer("é", "e", "è", "e", "É", "E", "ü", "u", "ç", "c").Replace
+
+func TestReader_headerNormalizer(t *testing.T) {
+	type accentType struct {
+		Prenom string `csv:"prenom"`
+		Annee  int    `csv:"annee_de_creation"`
+		Ville  string `csv:"ville|zürich_ville"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		opts           []ReaderOption
+		expectedRecord accentType
+		expectedErr    error
+	}{
+		{
+			name:           "strip accents",
+			data:           "prénom,annee_de_création,ville\nAndré,1999,Genève\n",
+			opts:           []ReaderOption{WithHeaderNormalizer(stripAccents)},
+			expectedRecord: accentType{Prenom: "André", Annee: 1999, Ville: "Genève"},
+		},
+		{
+			name:           "tags are normalised",
+			data:           "zurich_ville\nZürich\n",
+			opts:           []ReaderOption{WithHeaderNormalizer(stripAccents)},
+			expectedRecord: accentType{Ville: "Zürich"},
+		},
+		{
+			name:           "with case insensitive",
+			data:           "PRÉNOM\nÉlodie\n",
+			opts:           []ReaderOption{WithHeaderNormalizer(stripAccents), WithCaseInsensitiveHeaders()},
+			expectedRecord: accentType{Prenom: "Élodie"},
+		},
+		{
+			name:           "without normalizer",
+			data:           "prénom,prenom\nAndré,Andre\n",
+			expectedRecord: accentType{Prenom: "Andre"},
+		},
+		{
+			name:        "duplicate",
+			data:        "prénom,prenom\nAndré,Andre\n",
+			opts:        []ReaderOption{WithHeaderNormalizer(stripAccents)},
+			expectedErr: ErrDuplicateHeader,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*accentType](csv.NewReader(strings.NewReader(tc.data)), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record accentType
+			err = r.Read(&record)
+			if want, got := tc.expectedErr, err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_caseInsensitiveHeadersDuplicate(t *testing.T) {
+	data := "foo,Foo,bar\n1,2,3\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)), WithCaseInsensitiveHeaders())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if want, got := ErrDuplicateHeader, r.Read(&record); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestReader_required(t *testing.T) {
+	type requiredType struct {
+		UserID string `csv:"user_id,required"`
+		Name   string `csv:"name"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		expectedRecord requiredType
+		expectedErr    error
+	}{
+		{name: "required column present", data: "user_id\n42\n", expectedRecord: requiredType{UserID: "42"}},
+		{name: "required column missing", data: "name\nalice\n", expectedErr: ErrMissingRequiredColumn},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*requiredType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record requiredType
+			if want, got := tc.expectedErr, r.Read(&record); !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_Header(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if _, err := r.Header(); !errors.Is(err, ErrHeaderNotRead) {
+		t.Fatalf("expected error %v but got %v", ErrHeaderNotRead, err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	header, err := r.Header()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "foo,bar,baz", strings.Join(header, ","); want != got {
+		t.Fatalf("expected header %s but got %s", want, got)
+	}
+}
+
+func TestReader_pointer(t *testing.T) {
+	type nullableType struct {
+		Age  *int    `csv:"age"`
+		Name *string `csv:"name"`
+	}
+	data := "age,name\n42,alice\n,\n"
+	r, err := NewReader[*nullableType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 2, len(rows); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	if rows[0].Age == nil || *rows[0].Age != 42 {
+		t.Fatalf("expected age 42 but got %v", rows[0].Age)
+	}
+	if rows[0].Name == nil || *rows[0].Name != "alice" {
+		t.Fatalf("expected name alice but got %v", rows[0].Name)
+	}
+	if rows[1].Age != nil || rows[1].Name != nil {
+		t.Fatalf("expected nil fields for empty cells but got %+v", *rows[1])
+	}
+}
+
+func TestReader_pointerToPointer(t *testing.T) {
+	r := &Reader[*struct {
+		Field **int `csv:"field"`
+	}]{}
+	if want, got := ErrFieldNotAssignable, r.validateFields(); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestReader_unmarshaler(t *testing.T) {
+	type unmarshalerType struct {
+		Name string    `csv:"name"`
+		Tags listType  `csv:"tags"`
+		Opt  *listType `csv:"opt"`
+	}
+	data := "name,tags,opt\nalice,\"a,b,c\",d\nbob,,\n"
+	r, err := NewReader[*unmarshalerType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record unmarshalerType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "a|b|c", strings.Join(record.Tags, "|"); want != got {
+		t.Fatalf("expected tags %s but got %s", want, got)
+	}
+	if record.Opt == nil || len(*record.Opt) != 1 || (*record.Opt)[0] != "d" {
+		t.Fatalf("expected opt [d] but got %v", record.Opt)
+	}
+	var record2 unmarshalerType
+	err = r.Read(&record2)
+	if want, got := errEmptyList, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if !strings.Contains(err.Error(), "Tags") {
+		t.Fatalf("expected error to name field Tags but got %v", err)
+	}
+}
+
+func TestReader_recordError(t *testing.T) {
+	data := "name,age\nalice,1\n\"bob\nsmith\",x\n"
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	_, err = r.ReadAll()
+	var recErr *RecordError
+	if !errors.As(err, &recErr) {
+		t.Fatalf("expected record error but got %v", err)
+	}
+	expected := RecordError{Record: 3, Line: 4, Column: 8, Field: "Age"}
+	if want, got := expected, (RecordError{Record: recErr.Record, Line: recErr.Line, Column: recErr.Column, Field: recErr.Field}); want != got {
+		t.Fatalf("expected error position %+v but got %+v", want, got)
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatalf("expected underlying conversion error but got %v", err)
+	}
+}
+
+func TestReader_omitEmpty(t *testing.T) {
+	type omitEmptyType struct {
+		Name  string `csv:"name,omitempty"`
+		Age   int    `csv:"age,omitempty"`
+		Email string `csv:"email"`
+	}
+	data := "name,age,email\n,,\n"
+	r, err := NewReader[*omitEmptyType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	// Pre-set values are kept for omitempty fields only.
+	record := omitEmptyType{Name: "alice", Age: 42, Email: "alice@example.com"}
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (omitEmptyType{Name: "alice", Age: 42}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_csvReaderOptions(t *testing.T) {
+	data := "foo\tbar\tbaz\n# comment\n1\t2\the\"llo\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)), WithComma('\t'), WithComment('#'), WithLazyQuotes(true))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "1", Bar: "2", Baz: "he\"llo"}), record; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+}
+
+func TestReader_format(t *testing.T) {
+	testCases := [...]struct {
+		name   string
+		data   string
+		format Format
+	}{
+		{name: "CSV", data: exampleCSV, format: FormatCSV},
+		{name: "TSV", data: "foo\tbar\tbaz\n1\t2\thello\n", format: FormatTSV},
+		{name: "pipe", data: "foo|bar|baz\n1|2|hello\n", format: FormatPipe},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(tc.data)), WithFormat(tc.format))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record exampleType
+			if err := r.Read(&record); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := (exampleType{Foo: "1", Bar: "2", Baz: "hello"}), record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_commentPrefix(t *testing.T) {
+	testCases := [...]struct {
+		name            string
+		data            string
+		opts            []ReaderOption
+		expectedRecords []exampleType
+	}{
+		{
+			name:            "interspersed",
+			data:            "foo,bar,baz\n// first\n1,2,hello\n//second, with, more, fields\n3,2,world\n//\n",
+			expectedRecords: []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}},
+		},
+		{
+			name:            "quoted record fields",
+			data:            "foo,bar,baz\n1,\"// not, a comment\",\"hello\"\n// \"quoted\"\n",
+			opts:            []ReaderOption{WithLazyQuotes(true)},
+			expectedRecords: []exampleType{{Foo: "1", Bar: "// not, a comment", Baz: "hello"}},
+		},
+		{
+			name:            "without header",
+			data:            "// comment\n1,2,3\n",
+			opts:            []ReaderOption{WithoutHeader()},
+			expectedRecords: []exampleType{{Bar: "1", Baz: "2", Foo: "3"}},
+		},
+		{
+			name:            "header line",
+			data:            "// banner\nfoo,bar,baz\n// comment\n1,2,hello\n",
+			opts:            []ReaderOption{WithHeaderLine(2)},
+			expectedRecords: []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(tc.data)), append(tc.opts, WithCommentPrefix("//"))...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			records, err := r.ReadAll()
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := len(tc.expectedRecords), len(records); want != got {
+				t.Fatalf("expected %d records but got %d", want, got)
+			}
+			for i, record := range records {
+				if want, got := tc.expectedRecords[i], *record; want != got {
+					t.Fatalf("expecting %+v but got %+v", want, got)
+				}
+			}
+		})
+	}
+
+	// The header is not a comment.
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader("//foo,bar,baz\n1,2,hello\n")), WithCommentPrefix("//"))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Bar: "2", Baz: "hello"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if want, got := 2, r.Line(); want != got {
+		t.Fatalf("expected line %d but got %d", want, got)
+	}
+}
+
+// A pre-configured CSV reader is not changed without options.
+func TestReader_preconfiguredCSVReader(t *testing.T) {
+	rd := csv.NewReader(strings.NewReader("foo;bar;baz\n1;2;hello\n"))
+	rd.Comma = ';'
+	r, err := NewReader[*exampleType](rd)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "1", Bar: "2", Baz: "hello"}), record; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+}
+
+func TestReader_skip(t *testing.T) {
+	type skipType struct {
+		Foo string `csv:"foo"`
+		Bar string `csv:"-"`
+		Baz string `csv:"-,omitempty"`
+	}
+	// The header has a "-" column, which must not be mapped to either
+	// of the skipped fields.
+	data := "foo,bar,baz,-\n1,2,3,4\n"
+	r, err := NewReader[*skipType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record skipType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (skipType{Foo: "1"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_default(t *testing.T) {
+	type defaultType struct {
+		Name    string `csv:"name"`
+		Country string `csv:"country,default=US"`
+		Age     int    `csv:"age,default=18"`
+		Active  *bool  `csv:"active,default=true"`
+		Score   int    `csv:"score,default=5"`
+	}
+	// score is missing from the header
+	data := "name,country,age,active\nalice,UK,42,false\nbob,,,\ncarol\n"
+	rd := csv.NewReader(strings.NewReader(data))
+	rd.FieldsPerRecord = -1
+	r, err := NewReader[*defaultType](rd)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []struct {
+		name, country string
+		age           int
+		active        bool
+		score         int
+	}{
+		{name: "alice", country: "UK", age: 42, active: false, score: 5},
+		{name: "bob", country: "US", age: 18, active: true, score: 5},
+		{name: "carol", country: "US", age: 18, active: true, score: 5},
+	}
+	if want, got := len(expected), len(rows); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i, e := range expected {
+		row := rows[i]
+		if row.Name != e.name || row.Country != e.country || row.Age != e.age || row.Active == nil || *row.Active != e.active || row.Score != e.score {
+			t.Fatalf("expecting record %d to be %+v but got %+v", i, e, *row)
+		}
+	}
+}
+
+func TestReader_invalidDefault(t *testing.T) {
+	r := &Reader[*struct {
+		Age int `csv:"age,default=old"`
+	}]{}
+	var numErr *strconv.NumError
+	if err := r.validateFields(); !errors.As(err, &numErr) {
+		t.Fatalf("expected conversion error but got %v", err)
+	}
+}
+
+func TestReader_strictColumns(t *testing.T) {
+	testCases := [...]struct {
+		name          string
+		data          string
+		opts          []ReaderOption
+		expectedError *ColumnCountError
+	}{
+		{name: "same length", data: "foo,bar,baz\n1,2,3\n"},
+		{name: "fewer fields", data: "foo,bar,baz\n1,2,3\n1,2\n", expectedError: &ColumnCountError{Record: 3, Line: 3, Expected: 3, Actual: 2}},
+		{name: "more fields", data: "foo,bar,baz\n1,2,3,4\n", expectedError: &ColumnCountError{Record: 2, Line: 2, Expected: 3, Actual: 4}},
+		{name: "without header", data: "1,2,3\n1,2,3,4\n", opts: []ReaderOption{WithoutHeader()}, expectedError: &ColumnCountError{Record: 2, Line: 2, Expected: 3, Actual: 4}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rd := csv.NewReader(strings.NewReader(tc.data))
+			rd.FieldsPerRecord = -1
+			r, err := NewReader[*exampleType](rd, append(tc.opts, WithStrictColumns())...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			_, err = r.ReadAll()
+			if tc.expectedError == nil {
+				if err != nil {
+					t.Fatalf("expected no error but got %v", err)
+				}
+				return
+			}
+			var countErr *ColumnCountError
+			if !errors.As(err, &countErr) {
+				t.Fatalf("expected column count error but got %v", err)
+			}
+			if want, got := *tc.expectedError, *countErr; want != got {
+				t.Fatalf("expected error %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_maxRecordBytes(t *testing.T) {
+	data := "foo,bar,baz\n1,2,hello\n" + "3,4,\"" + strings.Repeat("x", 100) + "\"\n5,6,world\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)), WithMaxRecordBytes(16))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	record = exampleType{}
+	err = r.Read(&record)
+	var sizeErr *RecordSizeError
+	if !errors.As(err, &sizeErr) {
+		t.Fatalf("expected record size error but got %v", err)
+	}
+	if want, got := (RecordSizeError{Record: 3, Line: 3, Size: 102, Max: 16}), *sizeErr; want != got {
+		t.Fatalf("expected error %+v but got %+v", want, got)
+	}
+	// The oversized record is not stored.
+	if want, got := (exampleType{}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	// The next records are read as usual.
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "5", Bar: "6", Baz: "world"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_headerMap(t *testing.T) {
+	// untaggedType is a struct without csv tags, e.g. from another package
+	type untaggedType struct {
+		Name string
+		Age  int
+	}
+	data := "col1,col2\nalice,42\n"
+	r, err := NewReader[*untaggedType](csv.NewReader(strings.NewReader(data)), WithHeaderMap(map[string]string{"col1": "Name", "col2": "Age"}))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record untaggedType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (untaggedType{Name: "alice", Age: 42}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// Override the tag of Foo, Bar and Baz keep their tags
+	data2 := "foo,bar,baz,FOO\n1,2,3,4\n"
+	r2, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data2)), WithHeaderMap(map[string]string{"FOO": "Foo"}))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record2 exampleType
+	if err := r2.Read(&record2); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "4", Bar: "2", Baz: "3"}), record2; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_SetSchema(t *testing.T) {
+	type untaggedType struct {
+		Name  string
+		Age   int
+		Email string `csv:"email"`
+	}
+	r, err := NewReader[*untaggedType](csv.NewReader(strings.NewReader("full_name,years,email\nalice,42,a@example.com\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if err := r.SetSchema(map[string]string{"full_name": "Name", "years": "Age"}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	var record untaggedType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (untaggedType{Name: "alice", Age: 42, Email: "a@example.com"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// The next file has different header values.
+	r.Reset(csv.NewReader(strings.NewReader("mail,nom,age\nb@example.com,bob,7\n")))
+	if err := r.SetSchema(map[string]string{"nom": "Name", "age": "Age", "mail": "Email"}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	record = untaggedType{}
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (untaggedType{Name: "bob", Age: 7, Email: "b@example.com"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// The header already read is matched again. The tag of Email still
+	// maps it to the same record field.
+	r.Reset(csv.NewReader(strings.NewReader("nom,email\ncarol,c@example.com\ndave,d@example.com\n")))
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if err := r.SetSchema(map[string]string{"email": "Name"}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	record = untaggedType{}
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (untaggedType{Name: "d@example.com", Email: "d@example.com"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// An invalid schema keeps the previous one.
+	if want, got := ErrUnknownField, r.SetSchema(map[string]string{"nom": "Nom"}); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	r.Reset(csv.NewReader(strings.NewReader("email\ne@example.com\n")))
+	record = untaggedType{}
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (untaggedType{Name: "e@example.com", Email: "e@example.com"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_headerMapInvalid(t *testing.T) {
+	testCases := [...]struct {
+		name        string
+		headerMap   map[string]string
+		expectedErr error
+	}{
+		{name: "unknown field", headerMap: map[string]string{"col1": "Qux"}, expectedErr: ErrUnknownField},
+		{name: "field mapped twice", headerMap: map[string]string{"col1": "Foo", "col2": "Foo"}, expectedErr: ErrDuplicateField},
+		{name: "unexported field", headerMap: map[string]string{"col1": "qux"}, expectedErr: ErrUnexportedField},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewReader[*struct {
+				Foo string `csv:"foo"`
+				qux string
+			}](csv.NewReader(strings.NewReader("")), WithHeaderMap(tc.headerMap))
+			if want, got := tc.expectedErr, err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_trimSpace(t *testing.T) {
+	data := " name , age ,price\n hello ,  42\t, 9.99 \n"
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)), WithTrimSpace())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record numericType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (numericType{Name: "hello", Age: 42, Price: 9.99}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// Without the option, white space is preserved
+	r2, err := NewReader[*exampleType](csv.NewReader(strings.NewReader("foo,bar\n hello ,world\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record2 exampleType
+	if err := r2.Read(&record2); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: " hello ", Bar: "world"}), record2; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_readError(t *testing.T) {
+	testCases := [...]struct {
+		name           string
+		data           string
+		expectedRecord int
+		expectedErr    error
+	}{
+		{name: "bare quote in header", data: "foo,b\"ar\n", expectedRecord: 1, expectedErr: csv.ErrBareQuote},
+		{name: "bare quote in record", data: "foo,bar\n1,2\n3,4\"\n", expectedRecord: 3, expectedErr: csv.ErrBareQuote},
+		{name: "wrong number of fields", data: "foo,bar\n1,2,3\n", expectedRecord: 2, expectedErr: csv.ErrFieldCount},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			_, err = r.ReadAll()
+			var readErr *ReadError
+			if !errors.As(err, &readErr) {
+				t.Fatalf("expected read error but got %v", err)
+			}
+			if want, got := tc.expectedRecord, readErr.Record; want != got {
+				t.Fatalf("expected error in record %d but got %d", want, got)
+			}
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected underlying parse error but got %v", err)
+			}
+			if want, got := tc.expectedErr, err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_ReadInto(t *testing.T) {
+	data := "foo,bar,baz\n1,a,x\n2,b,y\n3,c,z\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	reused := &exampleType{}
+	dst := make([]*exampleType, 2)
+	dst[0] = reused
+	n, err := r.ReadInto(dst)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 2, n; want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	if dst[0] != reused {
+		t.Fatalf("expected non-nil element to be reused")
+	}
+	if want, got := (exampleType{Foo: "1", Bar: "a", Baz: "x"}), *dst[0]; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+	if want, got := (exampleType{Foo: "2", Bar: "b", Baz: "y"}), *dst[1]; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+
+	// Read the tail of the file
+	n, err = r.ReadInto(dst)
+	if err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+	if want, got := 1, n; want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	if want, got := (exampleType{Foo: "3", Bar: "c", Baz: "z"}), *dst[0]; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+}
+
+func TestReader_ReadIntoNilAfterEOF(t *testing.T) {
+	data := "foo,bar,baz\n1,a,x\n2,b,y\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	dst := make([]*exampleType, 4)
+	n, err := r.ReadInto(dst)
+	if err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+	if want, got := 2, n; want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := n; i < len(dst); i++ {
+		if dst[i] != nil {
+			t.Fatalf("expected element %d after the records read to be nil but got %v", i, dst[i])
+		}
+	}
+}
+
+func TestReader_ReadBatch(t *testing.T) {
+	data := "name,age\nalice,1\nbob,2\ncarol,3\n"
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	reused := &numericType{Name: "stale", Price: 9.99}
+	batch, err := r.ReadBatch(append(make([]*numericType, 0, 2), reused))
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 2, len(batch); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	if batch[0] != reused {
+		t.Fatalf("expected non-nil element to be reused")
+	}
+	// The values of the previous use are reset.
+	if want, got := (numericType{Name: "alice", Age: 1}), *batch[0]; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if want, got := (numericType{Name: "bob", Age: 2}), *batch[1]; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// Read the tail of the file into the same rows.
+	second, first := batch[1], batch[0]
+	batch, err = r.ReadBatch(batch[:0])
+	if err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+	if want, got := 1, len(batch); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	if batch[0] != first {
+		t.Fatalf("expected rows to be reused")
+	}
+	if want, got := (numericType{Name: "carol", Age: 3}), *batch[0]; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	// The row after the records read keeps the previous record.
+	if want, got := (numericType{Name: "bob", Age: 2}), *second; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// Reuse the batch again at the end of the file.
+	batch, err = r.ReadBatch(batch[:0])
+	if err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+	if want, got := 0, len(batch); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	if want, got := (numericType{Name: "carol", Age: 3}), *first; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if want, got := (numericType{Name: "bob", Age: 2}), *second; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_BOM(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader("\uFEFF" + exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "1", Bar: "2", Baz: "hello"}), record; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+
+	// Only the start of the file is affected
+	data := "\uFEFF1,\uFEFF2,hello\n\uFEFF3,2,world\n"
+	r2, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)), WithoutHeader())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows, err := r2.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	// Struct field order is Bar Baz Foo
+	if want, got := (exampleType{Bar: "1", Baz: "\uFEFF2", Foo: "hello"}), *rows[0]; want != got {
+		t.Fatalf("expecting %+q but got %+q", want, got)
+	}
+	if want, got := (exampleType{Bar: "\uFEFF3", Baz: "2", Foo: "world"}), *rows[1]; want != got {
+		t.Fatalf("expecting %+q but got %+q", want, got)
+	}
+}
+
+func TestReader_aliases(t *testing.T) {
+	type aliasType struct {
+		PostalCode string `csv:"postal_code|zip"`
+		Name       string `csv:"name"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		expectedRecord aliasType
+		expectedErr    error
+	}{
+		{name: "header", data: "name,postal_code\nalice,12345\n", expectedRecord: aliasType{PostalCode: "12345", Name: "alice"}},
+		{name: "alias", data: "zip,name\n12345,alice\n", expectedRecord: aliasType{PostalCode: "12345", Name: "alice"}},
+		{name: "none", data: "name\nalice\n", expectedRecord: aliasType{Name: "alice"}},
+		{name: "ambiguous", data: "zip,postal_code\n12345,67890\n", expectedErr: ErrAmbiguousHeader},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*aliasType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record aliasType
+			if want, got := tc.expectedErr, r.Read(&record); !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_Reset(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 2, len(records); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+
+	// The second file has a different column order.
+	r.Reset(csv.NewReader(strings.NewReader("baz,foo\nagain,5\n")))
+	if _, err := r.Header(); !errors.Is(err, ErrHeaderNotRead) {
+		t.Fatalf("expected error %v but got %v", ErrHeaderNotRead, err)
+	}
+	records, err = r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 1, len(records); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	if want, got := (exampleType{Foo: "5", Baz: "again"}), *records[0]; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	header, err := r.Header()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := []string{"baz", "foo"}, header; !slices.Equal(want, got) {
+		t.Fatalf("expected header %q but got %q", want, got)
+	}
+}
+
+// The number of record fields of WithStrictColumns is set again by the
+// first record of the next file.
+func TestReader_ResetStrictColumnsWithoutHeader(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader("1,2\n3,4\n")), WithoutHeader(), WithStrictColumns())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	r.Reset(csv.NewReader(strings.NewReader("1,2,3\n4,5,6\n")))
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 2, len(records); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+
+	files := []*csv.Reader{
+		csv.NewReader(strings.NewReader("1,2\n")),
+		csv.NewReader(strings.NewReader("1,2,3\n")),
+	}
+	r, err = NewMultiReader[*exampleType](files, WithoutHeader(), WithStrictColumns())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+}
+
+func TestNewMultiReader(t *testing.T) {
+	files := []*csv.Reader{
+		csv.NewReader(strings.NewReader(exampleCSV)),
+		csv.NewReader(strings.NewReader("")),
+		csv.NewReader(strings.NewReader("foo,bar,baz\n")),
+		// The second file has a different column order.
+		csv.NewReader(strings.NewReader("baz,foo\nagain,5\n")),
+	}
+	r, err := NewMultiReader[*exampleType](files)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []*exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}, {Foo: "5", Baz: "again"}}
+	if want, got := expected, records; !reflect.DeepEqual(want, got) {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	r, err = NewMultiReader[*exampleType](nil)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+}
+
+func TestNewMultiReader_error(t *testing.T) {
+	files := []*csv.Reader{
+		csv.NewReader(strings.NewReader("name,age\nfoo,1\n")),
+		csv.NewReader(strings.NewReader("age,name\nx,bar\n3,baz\n")),
+	}
+	r, err := NewMultiReader[*numericType](files)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record numericType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	// The errors refer to the line in the second file.
+	var recordErr *RecordError
+	if err := r.Read(&record); !errors.As(err, &recordErr) {
+		t.Fatalf("expected a *RecordError but got %v", err)
+	}
+	if want, got := 2, recordErr.Line; want != got {
+		t.Fatalf("expected error at line %d but got %d", want, got)
+	}
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (numericType{Name: "baz", Age: 3}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if want, got := 3, r.Line(); want != got {
+		t.Fatalf("expected line %d but got %d", want, got)
+	}
+
+	// Reset discards the files not yet read.
+	r, err = NewMultiReader[*numericType](files)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	r.Reset(csv.NewReader(strings.NewReader("")))
+	if err := r.Read(&record); err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+}
+
+func TestReader_Extras(t *testing.T) {
+	type restType struct {
+		Foo  string   `csv:"foo"`
+		Rest []string `csv:",rest"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		opts           []ReaderOption
+		expectedExtras [][]string
+	}{
+		{name: "all mapped", data: exampleCSV, expectedExtras: [][]string{nil, nil}},
+		{name: "unknown columns", data: "extra,foo,bar,baz,more\nx,1,2,hello,y\nz,3,2,world,\n", expectedExtras: [][]string{{"x", "y"}, {"z", ""}}},
+		{name: "beyond header", data: "foo,bar,baz\n1,2,hello,x\n3,2,world\n", expectedExtras: [][]string{{"x"}, nil}},
+		{name: "without header", data: "1,2,3,x\n", opts: []ReaderOption{WithoutHeader()}, expectedExtras: [][]string{{"x"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rd := csv.NewReader(strings.NewReader(tc.data))
+			rd.FieldsPerRecord = -1
+			r, err := NewReader[*exampleType](rd, tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			if extras := r.Extras(); extras != nil {
+				t.Fatalf("expected no extras before Read but got %q", extras)
+			}
+			for _, expected := range tc.expectedExtras {
+				var record exampleType
+				if err := r.Read(&record); err != nil {
+					t.Fatalf("expected no error but got %v", err)
+				}
+				if want, got := expected, r.Extras(); !slices.Equal(want, got) {
+					t.Fatalf("expected extras %q but got %q", want, got)
+				}
+			}
+		})
+	}
+
+	// The record fields beyond the header are stored in the rest field.
+	rd := csv.NewReader(strings.NewReader("foo,bar\n1,2,x\n"))
+	rd.FieldsPerRecord = -1
+	r, err := NewReader[*restType](rd)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record restType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := []string{"2"}, r.Extras(); !slices.Equal(want, got) {
+		t.Fatalf("expected extras %q but got %q", want, got)
+	}
+}
+
+func TestReader_Present(t *testing.T) {
+	type presentType struct {
+		Name    string   `csv:"name"`
+		Age     int      `csv:"age,omitempty"`
+		Country string   `csv:"country,default=US"`
+		City    string   `csv:"city"`
+		Label   string   `csv:"name"`
+		Rest    []string `csv:",rest"`
+	}
+	data := "name,age,country\nalice,42,UK\nbob,,\nNULL,7,FR,extra\ncarol\n"
+	rd := csv.NewReader(strings.NewReader(data))
+	rd.FieldsPerRecord = -1
+	r, err := NewReader[*presentType](rd, WithNullValues("NULL"))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if present := r.Present(); present != nil {
+		t.Fatalf("expected no present fields before Read but got %q", present)
+	}
+	expected := [][]string{
+		{"Name", "Age", "Country", "Label"},
+		// Empty record field values are not present, even with defaults.
+		{"Name", "Label"},
+		{"Age", "Country", "Rest"},
+		// The record is too short for the other fields.
+		{"Name", "Label"},
+	}
+	for _, want := range expected {
+		var record presentType
+		if err := r.Read(&record); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if got := r.Present(); !slices.Equal(want, got) {
+			t.Fatalf("expected present fields %q but got %q", want, got)
+		}
+	}
+}
+
+func TestReader_logger(t *testing.T) {
+	type loggedType struct {
+		Name    string `csv:"name"`
+		Age     int    `csv:"age"`
+		Country string `csv:"country,default=US"`
+		Email   string `csv:"email"`
+		City    string `csv:"city,omitempty"`
+	}
+	data := "name,age,country,city\nalice,42,,\nbob,x,UK,\nNULL,NULL,FR,Paris\n"
+	var events []Event
+	r, err := NewReader[*loggedType](csv.NewReader(strings.NewReader(data)),
+		WithNullValues("NULL"),
+		WithRecordErrorHandler(func(int, []string, error) error { return nil }),
+		WithLogger(func(e Event) { events = append(events, e) }))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []Event{
+		{Kind: EventUnmatched, Line: 1, Field: "Email"},
+		{Kind: EventDefaulted, Line: 2, Field: "Country"},
+		{Kind: EventSkipped, Line: 3},
+		{Kind: EventNull, Line: 4, Field: "Name"},
+		{Kind: EventNull, Line: 4, Field: "Age"},
+	}
+	if want, got := len(expected), len(events); want != got {
+		t.Fatalf("expected %d events but got %d: %+v", want, got, events)
+	}
+	for i, want := range expected {
+		got := events[i]
+		if want.Kind != got.Kind || want.Line != got.Line || want.Field != got.Field {
+			t.Fatalf("expected event %d to be %+v but got %+v", i, want, got)
+		}
+	}
+	if want, got := ErrConversion, events[2].Err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if want, got := "skipped", events[2].Kind.String(); want != got {
+		t.Fatalf("expected kind %q but got %q", want, got)
+	}
+}
+
+func TestReader_skipLines(t *testing.T) {
+	testCases := [...]struct {
+		name            string
+		data            string
+		opts            []ReaderOption
+		expectedRecords []exampleType
+		expectedErr     error
+	}{
+		{name: "no skip", data: exampleCSV, expectedRecords: []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}, expectedErr: io.EOF},
+		{name: "banner", data: "Exported report\ngenerated,today\n" + exampleCSV, opts: []ReaderOption{WithSkipLines(2)}, expectedRecords: []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}, expectedErr: io.EOF},
+		{name: "without header", data: "banner\n1,2,3\n", opts: []ReaderOption{WithSkipLines(1), WithoutHeader()}, expectedRecords: []exampleType{{Bar: "1", Baz: "2", Foo: "3"}}, expectedErr: io.EOF},
+		{name: "EOF while skipping", data: "banner\n", opts: []ReaderOption{WithSkipLines(2)}, expectedErr: io.EOF},
+		{name: "header line", data: "Report\n\ngenerated,today\n" + exampleCSV, opts: []ReaderOption{WithHeaderLine(3)}, expectedRecords: []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}, expectedErr: io.EOF},
+		{name: "header line overrides skip lines", data: "Report\n" + exampleCSV, opts: []ReaderOption{WithSkipLines(3), WithHeaderLine(2)}, expectedRecords: []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}, expectedErr: io.EOF},
+		{name: "EOF before header line", data: "Report\n", opts: []ReaderOption{WithHeaderLine(3)}, expectedErr: ErrHeaderLineNotFound},
+		{name: "EOF at header line", data: "Report\n", opts: []ReaderOption{WithHeaderLine(2)}, expectedErr: ErrHeaderLineNotFound},
+		{name: "EOF after header line", data: "Report\nfoo\n", opts: []ReaderOption{WithHeaderLine(2)}, expectedErr: io.EOF},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(tc.data)), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			for _, expected := range tc.expectedRecords {
+				var record exampleType
+				if err := r.Read(&record); err != nil {
+					t.Fatalf("expected no error but got %v", err)
+				}
+				if want, got := expected, record; want != got {
+					t.Fatalf("expecting %+v but got %+v", want, got)
+				}
+			}
+			var record exampleType
+			if want, got := tc.expectedErr, r.Read(&record); !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_fieldTransform(t *testing.T) {
+	type priceType struct {
+		Currency string  `csv:"currency,default=USD"`
+		Amount   float64 `csv:"amount"`
+	}
+	data := "currency,amount\n gbp ,1.5\n,2\n"
+	r, err := NewReader[*priceType](csv.NewReader(strings.NewReader(data)),
+		WithTrimSpace(),
+		WithFieldTransform("currency", strings.ToUpper),
+		WithFieldTransform("currency", func(s string) string { return strings.TrimSuffix(s, "P") }),
+		WithFieldTransform("amount", func(s string) string { return s + "0" }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []priceType{{Currency: "GB", Amount: 1.5}, {Currency: "USD", Amount: 20}}
+	if want, got := len(expected), len(records); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range expected {
+		if want, got := expected[i], *records[i]; want != got {
+			t.Fatalf("record %d: expecting %+v but got %+v", i, want, got)
+		}
+	}
+}
+
+func TestReader_fieldTransformUnknown(t *testing.T) {
+	_, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)), WithFieldTransform("qux", strings.ToUpper))
+	if want, got := ErrUnknownField, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+type jsonType struct {
+	Name     string             `csv:"name"`
+	Metadata map[string]string  `csv:"metadata,json"`
+	Tags     []string           `csv:"tags,json"`
+	Point    struct{ X, Y int } `csv:"point,json"`
+}
+
+func TestReader_json(t *testing.T) {
+	data := `name,metadata,tags,point
+alice,"{""team"":""a""}","[""x"",""y""]","{""X"":1,""Y"":2}"
+bob,,,
+`
+	r, err := NewReader[*jsonType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []jsonType{
+		{Name: "alice", Metadata: map[string]string{"team": "a"}, Tags: []string{"x", "y"}, Point: struct{ X, Y int }{1, 2}},
+		{Name: "bob"},
+	}
+	if want, got := len(expected), len(records); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range expected {
+		if want, got := expected[i], *records[i]; !reflect.DeepEqual(want, got) {
+			t.Fatalf("record %d: expecting %+v but got %+v", i, want, got)
+		}
+	}
+}
+
+func TestReader_jsonError(t *testing.T) {
+	r, err := NewReader[*jsonType](csv.NewReader(strings.NewReader("name,tags\nalice,[x]\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record jsonType
+	err = r.Read(&record)
+	var recErr *RecordError
+	if !errors.As(err, &recErr) {
+		t.Fatalf("expected *RecordError but got %v", err)
+	}
+	if want, got := (RecordError{Record: 2, Line: 2, Column: 7, Field: "Tags"}), (RecordError{Record: recErr.Record, Line: recErr.Line, Column: recErr.Column, Field: recErr.Field}); want != got {
+		t.Fatalf("expected error %+v but got %+v", want, got)
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected *json.SyntaxError but got %v", err)
+	}
+}
+
+func TestReader_bytes(t *testing.T) {
+	type bytesType struct {
+		Name string `csv:"name"`
+		Data []byte `csv:"data"`
+	}
+	r, err := NewReader[*bytesType](csv.NewReader(strings.NewReader("name,data\nalice,hello\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record bytesType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "hello", string(record.Data); want != got {
+		t.Fatalf("expected data %q but got %q", want, got)
+	}
+}
+
+func TestReader_disallowUnknownColumns(t *testing.T) {
+	testCases := [...]struct {
+		name        string
+		data        string
+		expectedErr error
+	}{
+		{name: "all known", data: "foo,bar,baz\n1,2,3\n"},
+		{name: "empty header value", data: "foo,,bar\n1,2,3\n"},
+		{name: "unknown", data: "foo,qux,bar,quux\n1,2,3,4\n", expectedErr: ErrUnknownColumn},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(tc.data)), WithDisallowUnknownColumns())
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record exampleType
+			err = r.Read(&record)
+			if want, got := tc.expectedErr, err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if err != nil && !strings.Contains(err.Error(), `["qux" "quux"]`) {
+				t.Fatalf("expected error to list all unknown columns but got %v", err)
+			}
+		})
+	}
+}
+
+type splitType struct {
+	Tags   []string `csv:"tags,split=;"`
+	Scores []int    `csv:"scores,split=|"`
+}
+
+func TestReader_split(t *testing.T) {
+	r, err := NewReader[*splitType](csv.NewReader(strings.NewReader("tags,scores\na;b;c,1|2\n,\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	// Empty record field values are nil slices.
+	expected := []splitType{{Tags: []string{"a", "b", "c"}, Scores: []int{1, 2}}, {}}
+	if want, got := len(expected), len(records); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range expected {
+		if want, got := expected[i], *records[i]; !reflect.DeepEqual(want, got) {
+			t.Fatalf("record %d: expecting %#v but got %#v", i, want, got)
+		}
+	}
+
+	r, err = NewReader[*splitType](csv.NewReader(strings.NewReader("scores\n1|x\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record splitType
+	if want, got := strconv.ErrSyntax, r.Read(&record); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestReader_splitUnsupported(t *testing.T) {
+	testCases := [...]struct {
+		name string
+		r    interface{ validateFields() error }
+	}{
+		{name: "slice without split", r: &Reader[*struct {
+			Tags []string `csv:"tags"`
+		}]{}},
+		{name: "split without slice", r: &Reader[*struct {
+			Tags string `csv:"tags,split=;"`
+		}]{}},
+		{name: "split of slices", r: &Reader[*struct {
+			Tags [][]byte `csv:"tags,split=;"`
+		}]{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if want, got := ErrFieldNotAssignable, tc.r.validateFields(); !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_Line(t *testing.T) {
+	data := "banner\nfoo,bar,baz\n1,\"multi\nline\",hello\n\n3,2,world\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)), WithSkipLines(1))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if want, got := 0, r.Line(); want != got {
+		t.Fatalf("expected line %d but got %d", want, got)
+	}
+	var record exampleType
+	for _, expectedLine := range []int{3, 6} {
+		if err := r.Read(&record); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if want, got := expectedLine, r.Line(); want != got {
+			t.Fatalf("expected line %d but got %d", want, got)
+		}
+	}
+	if want, got := io.EOF, r.Read(&record); want != got {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if want, got := 6, r.Line(); want != got {
+		t.Fatalf("expected line %d after EOF but got %d", want, got)
+	}
+}
+
+// Line is safe to call after a malformed record, which has no positions.
+func TestReader_LineAfterReadError(t *testing.T) {
+	testCases := [...]struct {
+		name         string
+		data         string
+		expectedLine int
+	}{
+		{name: "first record", data: "foo,bar\na\"b,c\n", expectedLine: 1},
+		{name: "after a record", data: "foo,bar\n1,2\na\"b,c\n", expectedLine: 2},
+		{name: "wrong number of fields", data: "foo,bar\n1,2\n1,2,3\n", expectedLine: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var readErr *ReadError
+			for {
+				var record exampleType
+				if err := r.Read(&record); errors.As(err, &readErr) {
+					break
+				} else if err != nil {
+					t.Fatalf("expected a *ReadError but got %v", err)
+				}
+			}
+			if want, got := tc.expectedLine, r.Line(); want != got {
+				t.Fatalf("expected line %d but got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_interface(t *testing.T) {
+	type anyType struct {
+		Foo any `csv:"foo"`
+		Baz any `csv:"baz,omitempty"`
+	}
+	r, err := NewReader[*anyType](csv.NewReader(strings.NewReader("foo,baz\n1,\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record anyType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	// Values are always stored as string.
+	if want, got := (anyType{Foo: "1"}), record; want != got {
+		t.Fatalf("expecting %#v but got %#v", want, got)
+	}
+
+	// Interface types not implemented by string are not supported.
+	_, err = NewReader[*struct {
+		Bar fmt.Stringer `csv:"bar"`
+	}](csv.NewReader(strings.NewReader("bar\n1\n")))
+	if want, got := ErrFieldNotAssignable, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestInferType(t *testing.T) {
+	testCases := [...]struct {
+		value    string
+		expected any
+	}{
+		{value: "42", expected: 42},
+		{value: "-7", expected: -7},
+		{value: "0", expected: 0},
+		{value: "007", expected: "007"},
+		{value: "+7", expected: "+7"},
+		{value: "-0", expected: -0.0},
+		{value: "1.50", expected: 1.5},
+		{value: "-0.5", expected: -0.5},
+		{value: "1e3", expected: 1000.0},
+		{value: "99999999999999999999", expected: 1e20},
+		{value: "01.5", expected: "01.5"},
+		{value: ".5", expected: ".5"},
+		{value: "1.", expected: "1."},
+		{value: "NaN", expected: "NaN"},
+		{value: "Inf", expected: "Inf"},
+		{value: "0x1p-2", expected: "0x1p-2"},
+		{value: "1_000", expected: "1_000"},
+		{value: "true", expected: true},
+		{value: "FALSE", expected: false},
+		{value: "t", expected: "t"},
+		{value: "tRuE", expected: "tRuE"},
+		{value: "", expected: ""},
+		{value: "hello", expected: "hello"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			if want, got := tc.expected, inferType(tc.value); want != got {
+				t.Fatalf("expected %#v but got %#v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_inferTypes(t *testing.T) {
+	type anyType struct {
+		Foo any    `csv:"foo"`
+		Bar any    `csv:"bar"`
+		Baz string `csv:"baz"`
+	}
+	r, err := NewReader[*anyType](csv.NewReader(strings.NewReader("foo,bar,baz\n1,true,2\n")), WithInferTypes())
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record anyType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (anyType{Foo: 1, Bar: true, Baz: "2"}), record; want != got {
+		t.Fatalf("expecting %#v but got %#v", want, got)
+	}
+}
+
+func TestReader_newlines(t *testing.T) {
+	data := "foo,bar,baz\n\"line 1\nline 2\r\nline 3\",2,\"\n\"\n"
+	testCases := [...]struct {
+		name           string
+		opts           []ReaderOption
+		expectedRecord exampleType
+	}{
+		{name: "default", expectedRecord: exampleType{Foo: "line 1\nline 2\nline 3", Bar: "2", Baz: "\n"}},
+		{name: "replace", opts: []ReaderOption{WithReplaceNewlines(" ")}, expectedRecord: exampleType{Foo: "line 1 line 2 line 3", Bar: "2", Baz: " "}},
+		{name: "remove", opts: []ReaderOption{WithReplaceNewlines("")}, expectedRecord: exampleType{Foo: "line 1line 2line 3", Bar: "2"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record exampleType
+			if err := r.Read(&record); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+type restType struct {
+	Foo  string   `csv:"foo"`
+	Bar  string   `csv:"bar"`
+	Rest []string `csv:",rest"`
+}
+
+func TestReader_rest(t *testing.T) {
+	testCases := [...]struct {
+		name            string
+		data            string
+		opts            []ReaderOption
+		expectedRecords []restType
+	}{
+		{
+			name:            "header",
+			data:            "foo,baz,bar\n1,3,2,4,5\n1,3,2\n",
+			expectedRecords: []restType{{Foo: "1", Bar: "2", Rest: []string{"4", "5"}}, {Foo: "1", Bar: "2"}},
+		},
+		{
+			name:            "without header",
+			data:            "1,2,4,5\n1,2\n",
+			opts:            []ReaderOption{WithoutHeader()},
+			expectedRecords: []restType{{Foo: "1", Bar: "2", Rest: []string{"4", "5"}}, {Foo: "1", Bar: "2"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rd := csv.NewReader(strings.NewReader(tc.data))
+			rd.FieldsPerRecord = -1
+			r, err := NewReader[*restType](rd, tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			// Reuse the row to check that the rest field is reset.
+			var record restType
+			for i, expected := range tc.expectedRecords {
+				if err := r.Read(&record); err != nil {
+					t.Fatalf("expected no error but got %v", err)
+				}
+				if want, got := expected, record; !reflect.DeepEqual(want, got) {
+					t.Fatalf("record %d: expecting %#v but got %#v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReader_restInvalid(t *testing.T) {
+	testCases := [...]struct {
+		name        string
+		r           interface{ validateFields() error }
+		expectedErr error
+	}{
+		{name: "not a slice", r: &Reader[*struct {
+			Rest string `csv:",rest"`
+		}]{}, expectedErr: ErrFieldNotAssignable},
+		{name: "not a string slice", r: &Reader[*struct {
+			Rest []int `csv:",rest"`
+		}]{}, expectedErr: ErrFieldNotAssignable},
+		{name: "more than one", r: &Reader[*struct {
+			Rest  []string `csv:",rest"`
+			Rest2 []string `csv:",rest"`
+		}]{}, expectedErr: ErrDuplicateRest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if want, got := tc.expectedErr, tc.r.validateFields(); !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_duration(t *testing.T) {
+	type durationType struct {
+		Name    string         `csv:"name"`
+		Timeout time.Duration  `csv:"timeout"`
+		Retry   *time.Duration `csv:"retry"`
+	}
+	r, err := NewReader[*durationType](csv.NewReader(strings.NewReader("name,timeout,retry\na,30s,1h15m\nb,0,\nc,30,\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	retry := time.Hour + 15*time.Minute
+	for _, expected := range []durationType{{Name: "a", Timeout: 30 * time.Second, Retry: &retry}, {Name: "b"}} {
+		var record durationType
+		if err := r.Read(&record); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if want, got := expected, record; !reflect.DeepEqual(want, got) {
+			t.Fatalf("expecting %+v but got %+v", want, got)
+		}
+	}
+	var record durationType
+	err = r.Read(&record)
+	var recErr *RecordError
+	if !errors.As(err, &recErr) {
+		t.Fatalf("expected *RecordError but got %v", err)
+	}
+	if want, got := (RecordError{Record: 4, Line: 4, Column: 3, Field: "Timeout"}), (RecordError{Record: recErr.Record, Line: recErr.Line, Column: recErr.Column, Field: recErr.Field}); want != got {
+		t.Fatalf("expected error %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_complex(t *testing.T) {
+	type complexType struct {
+		Z   complex128  `csv:"z"`
+		Z64 complex64   `csv:"z64"`
+		Ptr *complex128 `csv:"ptr"`
+	}
+	z := complex(0, -1)
+	testCases := [...]struct {
+		name           string
+		data           string
+		expectedRecord complexType
+		expectedErr    error
+	}{
+		{name: "valid", data: "z,z64,ptr\n(1+2i),1.5e3,-1i\n", expectedRecord: complexType{Z: 1 + 2i, Z64: 1500, Ptr: &z}},
+		{name: "empty pointer", data: "z,ptr\n3-4i,\n", expectedRecord: complexType{Z: 3 - 4i}},
+		{name: "malformed", data: "z\n1+2j\n", expectedErr: strconv.ErrSyntax},
+		{name: "out of range", data: "z64\n1e40+1i\n", expectedErr: strconv.ErrRange},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*complexType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record complexType
+			err = r.Read(&record)
+			if want, got := tc.expectedErr, err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if err != nil {
+				var recErr *RecordError
+				if !errors.As(err, &recErr) || recErr.Line != 2 || !errors.Is(err, ErrConversion) {
+					t.Fatalf("expected *RecordError of invalid value on line 2 but got %v", err)
+				}
+				return
+			}
+			if want, got := tc.expectedRecord, record; !reflect.DeepEqual(want, got) {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_rejectNonFinite(t *testing.T) {
+	type floatType struct {
+		Price   float64    `csv:"price"`
+		Ratio   *float32   `csv:"ratio"`
+		Z       complex128 `csv:"z"`
+		Scores  []float64  `csv:"scores,split=;"`
+		Comment string     `csv:"comment"`
+	}
+	ratio, negInf := float32(0.5), float32(math.Inf(-1))
+	testCases := [...]struct {
+		name           string
+		data           string
+		opts           []ReaderOption
+		expectedRecord floatType
+		expectedErr    error
+	}{
+		{name: "finite", data: "price,ratio,z,scores,comment\n9.99,0.5,1+2i,1;2,Inf\n", opts: []ReaderOption{WithRejectNonFinite()}, expectedRecord: floatType{Price: 9.99, Ratio: &ratio, Z: 1 + 2i, Scores: []float64{1, 2}, Comment: "Inf"}},
+		{name: "NaN", data: "price\nNaN\n", opts: []ReaderOption{WithRejectNonFinite()}, expectedErr: ErrNonFinite},
+		{name: "Inf", data: "price\nInf\n", opts: []ReaderOption{WithRejectNonFinite()}, expectedErr: ErrNonFinite},
+		{name: "negative infinity", data: "ratio\n-infinity\n", opts: []ReaderOption{WithRejectNonFinite()}, expectedErr: ErrNonFinite},
+		{name: "complex", data: "z\n(1+Infi)\n", opts: []ReaderOption{WithRejectNonFinite()}, expectedErr: ErrNonFinite},
+		{name: "split", data: "scores\n1;NaN\n", opts: []ReaderOption{WithRejectNonFinite()}, expectedErr: ErrNonFinite},
+		{name: "out of range", data: "price\n1e400\n", opts: []ReaderOption{WithRejectNonFinite()}, expectedErr: strconv.ErrRange},
+		{name: "allowed by default", data: "price,ratio\n+Inf,-Inf\n", expectedRecord: floatType{Price: math.Inf(1), Ratio: &negInf}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*floatType](csv.NewReader(strings.NewReader(tc.data)), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record floatType
+			err = r.Read(&record)
+			if want, got := tc.expectedErr, err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if err != nil {
+				var recErr *RecordError
+				if !errors.As(err, &recErr) || recErr.Field == "" || !strings.Contains(err.Error(), strings.Split(tc.data, "\n")[1]) {
+					t.Fatalf("expected *RecordError naming the field and value but got %v", err)
+				}
+				return
+			}
+			if want, got := tc.expectedRecord, record; !reflect.DeepEqual(want, got) {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+// A non-finite default value is rejected when the Reader is created,
+// rather than failing for every record without the record field.
+func TestReader_rejectNonFiniteDefault(t *testing.T) {
+	type defaultType struct {
+		A string  `csv:"a"`
+		F float64 `csv:"f,default=NaN"`
+	}
+	rd := csv.NewReader(strings.NewReader("a,f\nx\n"))
+	rd.FieldsPerRecord = -1
+	_, err := NewReader[*defaultType](rd, WithRejectNonFinite())
+	if want, got := ErrInvalidDefault, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if want, got := ErrNonFinite, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+
+	// Without the option, NaN is a valid default value.
+	r, err := NewReader[*defaultType](csv.NewReader(strings.NewReader("a,f\nx,\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record defaultType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if !math.IsNaN(record.F) {
+		t.Fatalf("expected NaN but got %v", record.F)
+	}
+}
+
+// The position of a record error is only set for record fields of the
+// record, so a short record does not panic.
+func TestReader_recordErrorShortRecord(t *testing.T) {
+	rd := csv.NewReader(strings.NewReader("foo,bar\nx\n"))
+	rd.FieldsPerRecord = -1
+	r, err := NewReader[*exampleType](rd)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	recErr := r.recordError([]string{"x"}, 1, "Bar", ErrConversion)
+	if want, got := (RecordError{Record: 2, Field: "Bar", Err: ErrConversion}), *recErr; want != got {
+		t.Fatalf("expected error %+v but got %+v", want, got)
+	}
+	recErr = r.recordError([]string{"x"}, 0, "Foo", ErrConversion)
+	if want, got := (RecordError{Record: 2, Line: 2, Column: 1, Field: "Foo", Err: ErrConversion}), *recErr; want != got {
+		t.Fatalf("expected error %+v but got %+v", want, got)
+	}
+}
+
+func TestReader_numberSeparators(t *testing.T) {
+	type amountType struct {
+		Name   string   `csv:"name"`
+		Amount float64  `csv:"amount"`
+		Count  int      `csv:"count,default=1000"`
+		Ratio  *float32 `csv:"ratio"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		opts           []ReaderOption
+		expectedRecord amountType
+	}{
+		{
+			name:           "default",
+			data:           "name,amount,count\n1.234,1234.56,1234\n",
+			expectedRecord: amountType{Name: "1.234", Amount: 1234.56, Count: 1234},
+		},
+		{
+			name:           "US",
+			data:           "name,amount,count\n\"1,234\",\"1,234.56\",\"1,234\"\n",
+			opts:           []ReaderOption{WithThousandsSeparator(',')},
+			expectedRecord: amountType{Name: "1,234", Amount: 1234.56, Count: 1234},
+		},
+		{
+			name:           "EU",
+			data:           "name;amount;count\n1.234;1.234,56;\n",
+			opts:           []ReaderOption{WithComma(';'), WithDecimalSeparator(','), WithThousandsSeparator('.')},
+			expectedRecord: amountType{Name: "1.234", Amount: 1234.56, Count: 1000},
+		},
+		{
+			name:           "space and trim",
+			data:           "name;amount;count\nx; 1 234,56 ; 1 234\n",
+			opts:           []ReaderOption{WithComma(';'), WithTrimSpace(), WithDecimalSeparator(','), WithThousandsSeparator(' ')},
+			expectedRecord: amountType{Name: "x", Amount: 1234.56, Count: 1234},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*amountType](csv.NewReader(strings.NewReader(tc.data)), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record amountType
+			if err := r.Read(&record); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+
+	r, err := NewReader[*amountType](csv.NewReader(strings.NewReader("ratio\n\"0,5\"\n")), WithDecimalSeparator(','))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record amountType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := float32(0.5), *record.Ratio; want != got {
+		t.Fatalf("expected ratio %v but got %v", want, got)
+	}
+}
+
+func TestReader_Skip(t *testing.T) {
+	data := "foo,bar,baz\n1,2,hello\n3,2,world\n5,6,again\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	// Skip reads the header before skipping the first record.
+	if err := r.Skip(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "3", Bar: "2", Baz: "world"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if err := r.Skip(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := io.EOF, r.Skip(); want != got {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestReader_Peek(t *testing.T) {
+	rd := csv.NewReader(strings.NewReader(exampleCSV))
+	rd.ReuseRecord = true
+	r, err := NewReader[*exampleType](rd)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	first := exampleType{Foo: "1", Bar: "2", Baz: "hello"}
+	second := exampleType{Foo: "3", Bar: "2", Baz: "world"}
+	for i := 0; i < 2; i++ {
+		peeked, err := r.Peek()
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if want, got := first, *peeked; want != got {
+			t.Fatalf("expecting peeked %+v but got %+v", want, got)
+		}
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := first, record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if _, err := r.Peek(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	var records []exampleType
+	for row, err := range r.All() {
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		records = append(records, *row)
+	}
+	if want, got := []exampleType{second}, records; !slices.Equal(want, got) {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if _, err := r.Peek(); err != io.EOF {
+		t.Fatalf("expected error %v but got %v", io.EOF, err)
+	}
+}
+
+func TestReader_enum(t *testing.T) {
+	type enumType struct {
+		Status string  `csv:"status,enum=active|inactive|pending"`
+		Level  *string `csv:"level,enum=Low|High,enumfold"`
+		Code   int     `csv:"code,enum=1|2|3"`
+	}
+	pending, high := "pending", "High"
+	testCases := [...]struct {
+		name           string
+		data           string
+		expectedRecord enumType
+		expectedErr    error
+	}{
+		{name: "valid", data: "status,level,code\npending, hIGH ,2\n", expectedRecord: enumType{Status: pending, Level: &high, Code: 2}},
+		{name: "empty pointer", data: "status,level\nactive,\n", expectedRecord: enumType{Status: "active"}},
+		{name: "case sensitive", data: "status\nActive\n", expectedErr: ErrInvalidEnum},
+		{name: "empty", data: "status\n\"\"\n", expectedErr: ErrInvalidEnum},
+		{name: "invalid number", data: "code\n4\n", expectedErr: ErrInvalidEnum},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*enumType](csv.NewReader(strings.NewReader(tc.data)))
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record enumType
+			err = r.Read(&record)
+			if want, got := tc.expectedErr, err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+			if err != nil {
+				var recErr *RecordError
+				if !errors.As(err, &recErr) || recErr.Line != 2 {
+					t.Fatalf("expected *RecordError on line 2 but got %v", err)
+				}
+				return
+			}
+			if want, got := tc.expectedRecord, record; !reflect.DeepEqual(want, got) {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+
+	_, err := NewReader[*struct {
+		Status string `csv:"status,enum=a|b,default=c"`
+	}](csv.NewReader(strings.NewReader("")))
+	if want, got := ErrInvalidEnum, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestReader_nullValues(t *testing.T) {
+	type nullType struct {
+		Name    string   `csv:"name"`
+		Count   int      `csv:"count"`
+		Ratio   *float64 `csv:"ratio"`
+		Country string   `csv:"country,default=US"`
+		Note    string   `csv:"note,omitempty"`
+	}
+	data := "name,count,ratio,country,note\n" +
+		"NULL,N/A,-,null,n/a\n" +
+		"alice, NULL ,0.5,GB,-\n"
+	half, one := 0.5, 1.0
+	testCases := [...]struct {
+		name            string
+		opts            []ReaderOption
+		expectedRecords []nullType
+		expectedErr     error
+	}{
+		{
+			name:        "none",
+			expectedErr: ErrConversion,
+		},
+		{
+			name: "case sensitive",
+			opts: []ReaderOption{WithNullValues("NULL", "N/A", "-"), WithTrimSpace()},
+			expectedRecords: []nullType{
+				{Name: "", Count: 0, Country: "null", Note: "n/a"},
+				{Name: "alice", Count: 0, Ratio: &half, Country: "GB", Note: "note"},
+			},
+		},
+		{
+			name: "case insensitive",
+			opts: []ReaderOption{WithNullValuesFold("NULL", "N/A", "-"), WithTrimSpace()},
+			expectedRecords: []nullType{
+				{Name: "", Count: 0, Country: "US", Note: "note"},
+				{Name: "alice", Count: 0, Ratio: &half, Country: "GB", Note: "note"},
+			},
+		},
+		{
+			name:        "untrimmed",
+			opts:        []ReaderOption{WithNullValuesFold("NULL", "N/A", "-")},
+			expectedErr: ErrConversion,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*nullType](csv.NewReader(strings.NewReader(data)), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var records []nullType
+			for {
+				record := nullType{Name: "name", Count: 1, Ratio: &one, Note: "note"}
+				err := r.Read(&record)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if want, got := tc.expectedErr, err; want == nil || !errors.Is(got, want) {
+						t.Fatalf("expected error %v but got %v", want, got)
+					}
+					return
+				}
+				records = append(records, record)
+			}
+			if tc.expectedErr != nil {
+				t.Fatalf("expected error %v but got none", tc.expectedErr)
+			}
+			if want, got := tc.expectedRecords, records; !reflect.DeepEqual(want, got) {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_sharedHeader(t *testing.T) {
+	type sharedType struct {
+		Raw     string `csv:"foo"`
+		Parsed  int    `csv:"foo"`
+		Bar     string `csv:"bar"`
+		Postal  string `csv:"postal_code|zip"`
+		Zip     string `csv:"zip"`
+		Default string `csv:"baz,default=x"`
+		Missing string `csv:"baz"`
+	}
+	testCases := [...]struct {
+		name           string
+		data           string
+		opts           []ReaderOption
+		expectedRecord sharedType
+	}{
+		{
+			name:           "header",
+			data:           "bar,foo,zip\nb,42,12345\n",
+			expectedRecord: sharedType{Raw: "42", Parsed: 42, Bar: "b", Postal: "12345", Zip: "12345", Default: "x"},
+		},
+		{
+			name:           "transform",
+			data:           "foo\n 42\n",
+			opts:           []ReaderOption{WithFieldTransform("foo", strings.TrimSpace)},
+			expectedRecord: sharedType{Raw: "42", Parsed: 42, Default: "x"},
+		},
+		{
+			// Fields share the position of the first field with the header value.
+			name:           "without header",
+			data:           "42,b,12345,y\n",
+			opts:           []ReaderOption{WithoutHeader()},
+			expectedRecord: sharedType{Raw: "42", Parsed: 42, Bar: "b", Postal: "12345", Zip: "y", Default: "x"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader[*sharedType](csv.NewReader(strings.NewReader(tc.data)), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			var record sharedType
+			if err := r.Read(&record); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := tc.expectedRecord, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReader_recordErrorHandler(t *testing.T) {
+	data := "name,age,small\n" +
+		"alice,42,1\n" +
+		"bob,forty,2\n" +
+		"carol,7\n" +
+		"dave,8,300\n" +
+		"erin,9,3\n"
+	type handled struct {
+		line   int
+		record []string
+		err    error
+	}
+	var calls []handled
+	handler := func(line int, record []string, err error) error {
+		calls = append(calls, handled{line: line, record: slices.Clone(record), err: err})
+		return nil
+	}
+	rd := csv.NewReader(strings.NewReader(data))
+	rd.FieldsPerRecord = -1
+	r, err := NewReader[*numericType](rd, WithStrictColumns(), WithRecordErrorHandler(handler))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var names []string
+	for row, err := range r.All() {
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		names = append(names, row.Name)
+	}
+	if want, got := []string{"alice", "erin"}, names; !slices.Equal(want, got) {
+		t.Fatalf("expected rows %v but got %v", want, got)
+	}
+	expected := []struct {
+		line   int
+		record []string
+		err    error
+	}{
+		{line: 3, record: []string{"bob", "forty", "2"}, err: ErrConversion},
+		{line: 4, record: []string{"carol", "7"}, err: ErrColumnCount},
+		{line: 5, record: []string{"dave", "8", "300"}, err: ErrConversion},
+	}
+	if want, got := len(expected), len(calls); want != got {
+		t.Fatalf("expected %d calls of handler but got %d", want, got)
+	}
+	for i, want := range expected {
+		got := calls[i]
+		if want.line != got.line || !slices.Equal(want.record, got.record) || !errors.Is(got.err, want.err) {
+			t.Fatalf("expected call %d with line %d, record %q and error %v but got line %d, record %q and error %v",
+				i, want.line, want.record, want.err, got.line, got.record, got.err)
+		}
+	}
+
+	// The row is restored when a record is skipped.
+	r, err = NewReader[*numericType](csv.NewReader(strings.NewReader("name,age\nbob,x\n")), WithRecordErrorHandler(handler))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	record := numericType{Name: "unchanged"}
+	if want, got := io.EOF, r.Read(&record); want != got {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if want, got := (numericType{Name: "unchanged"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// The error of the handler is returned.
+	errStop := errors.New("stop")
+	r, err = NewReader[*numericType](csv.NewReader(strings.NewReader(data)), WithRecordErrorHandler(func(int, []string, error) error {
+		return errStop
+	}))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows, err := r.ReadAll()
+	if want, got := errStop, err; want != got {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if want, got := 1, len(rows); want != got {
+		t.Fatalf("expected %d rows but got %d", want, got)
+	}
+}
+
+func TestReadValue(t *testing.T) {
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader("name,age\nfoo,1\nbar,x\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	record, err := ReadValue(r)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (numericType{Name: "foo", Age: 1}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	// The zero value is returned with errors.
+	record, err = ReadValue(r)
+	if want, got := ErrConversion, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if want, got := (numericType{}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if _, err := ReadValue(r); err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+}
+
+// Embedded structs for TestReader_embedded and TestWriter_embedded.
+type (
+	embeddedID struct {
+		ID int `csv:"id"`
+	}
+	embeddedAddress struct {
+		embeddedID
+		Street string `csv:"street"`
+		City   string `csv:"city"`
+	}
+	EmbeddedContact struct {
+		Email string `csv:"email"`
+	}
+	embeddedNote struct {
+		Note string `csv:"note"`
+	}
+	embeddedCycle struct {
+		*embeddedCycle
+		Next *embeddedCycle
+		Name string `csv:"cycle"`
+	}
+	embeddedType struct {
+		embeddedAddress
+		*EmbeddedContact
+		embeddedNote `csv:"-"`
+		embeddedCycle
+		Name string `csv:"name"`
+	}
+)
+
+func TestReader_embedded(t *testing.T) {
+	data := "name,id,street,city,email,note,cycle\nalice,1,Main St,Springfield,a@example.com,hi,c\nbob,2,,,,,\n"
+	rows, err := Unmarshal[*embeddedType]([]byte(data))
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 2, len(rows); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	alice := rows[0]
+	if want, got := (embeddedAddress{embeddedID: embeddedID{ID: 1}, Street: "Main St", City: "Springfield"}), alice.embeddedAddress; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+	if alice.EmbeddedContact == nil {
+		t.Fatalf("expected embedded pointer to be allocated")
+	}
+	if want, got := "a@example.com", alice.Email; want != got {
+		t.Fatalf("expected email %q but got %q", want, got)
+	}
+	if want, got := "", alice.Note; want != got {
+		t.Fatalf("expected skipped note %q but got %q", want, got)
+	}
+	if want, got := "c", alice.embeddedCycle.Name; want != got {
+		t.Fatalf("expected cycle %q but got %q", want, got)
+	}
+	if want, got := "alice", alice.Name; want != got {
+		t.Fatalf("expected name %q but got %q", want, got)
+	}
+	// Empty record field values are set, so the pointer is allocated.
+	if rows[1].EmbeddedContact == nil {
+		t.Fatalf("expected embedded pointer to be allocated for an empty value")
+	}
+
+	r, err := NewReader[*embeddedType](csv.NewReader(strings.NewReader("id,town\n3,Paris\n")), WithHeaderMap(map[string]string{"town": "City"}))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record embeddedType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 3, record.ID; want != got {
+		t.Fatalf("expected id %d but got %d", want, got)
+	}
+	if record.EmbeddedContact != nil {
+		t.Fatalf("expected embedded pointer without record fields to be nil")
+	}
+	if want, got := []string{"ID", "City"}, r.Present(); !slices.Equal(want, got) {
+		t.Fatalf("expected present fields %q but got %q", want, got)
+	}
+
+	// Tagged embedded structs are not promoted.
+	_, err = NewReader[*struct {
+		EmbeddedContact `csv:"contact"`
+	}](csv.NewReader(strings.NewReader(exampleCSV)))
+	if want, got := ErrFieldNotAssignable, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+// Named types based on the supported kinds of struct fields.
+type (
+	currencyType string
+	countType    int
+	levelType    uint8
+	rateType     float64
+	flagType     bool
+)
+
+// namedType has struct fields of named types.
+type namedType struct {
+	Currency currencyType   `csv:"currency"`
+	Count    countType      `csv:"count"`
+	Level    levelType      `csv:"level"`
+	Rate     rateType       `csv:"rate"`
+	Active   flagType       `csv:"active,true=yes,false=no"`
+	Previous *currencyType  `csv:"previous"`
+	Accepted []currencyType `csv:"accepted,split=;"`
+	Status   currencyType   `csv:"status,enum=GBP|USD"`
+}
+
+func TestReader_namedTypes(t *testing.T) {
+	data := "currency,count,level,rate,active,previous,accepted,status\n" +
+		"GBP,-3,255,0.25,yes,USD,GBP;EUR,USD\n" +
+		"EUR,0,0,1,no,,,GBP\n"
+	r, err := NewReader[*namedType](csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	usd := currencyType("USD")
+	expected := []*namedType{
+		{Currency: "GBP", Count: -3, Level: 255, Rate: 0.25, Active: true, Previous: &usd, Accepted: []currencyType{"GBP", "EUR"}, Status: "USD"},
+		{Currency: "EUR", Count: 0, Level: 0, Rate: 1, Active: false, Status: "GBP"},
+	}
+	if want, got := expected, rows; !reflect.DeepEqual(want, got) {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	r, err = NewReader[*namedType](csv.NewReader(strings.NewReader("level\n256\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record namedType
+	if want, got := ErrConversion, r.Read(&record); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+// Misused row values return error instead of panicking.
+func TestReader_notSettable(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	if want, got := ErrNilRow, r.Read(nil); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	// The record is not consumed by the failed Read.
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "1", Bar: "2", Baz: "hello"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// Readers not created by NewReader are not validated.
+	rv := &Reader[exampleType]{}
+	if want, got := ErrNotPointer, rv.assignFields([]string{"1"}, exampleType{}); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	rs := &Reader[*string]{}
+	if want, got := ErrNotStructPointer, rs.assignFields([]string{"1"}, new(string)); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+// If a file has no header, Reader will return the underlying io.EOF error.
+func TestReader_emptyFile(t *testing.T) {
+	emptyFile := ``
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(emptyFile)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != io.EOF {
+		t.Fatalf("expected EOF error but got none")
+	}
+	// Header tells an empty file from a file with only header.
+	if want, got := ErrHeaderNotRead, errOf(r.Header()); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+// If a file has only header, it's equivalent to reading
+// an empty file with Reader because Reader will process
+// the header then try to read the content.
+func TestReader_headerOnly(t *testing.T) {
+	headerOnly := "foo,bar,baz\n"
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(headerOnly)))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != io.EOF {
+		t.Fatalf("expected EOF error but got none")
+	}
+	header, err := r.Header()
+	if err != nil {
+		t.Fatalf("expected no error for header but got %v", err)
+	}
+	if want, got := []string{"foo", "bar", "baz"}, header; !slices.Equal(want, got) {
+		t.Fatalf("expected header %q but got %q", want, got)
+	}
+}
+
+// errOf returns the error of a call with two results.
+func errOf[T any](_ T, err error) error { return err }
+
+func ExampleReader() {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for {
+		var record exampleType
+		if err := r.Read(&record); err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Fatal(err)
+		}
+		fmt.Printf("%+v\n", record)
+	}
+	// Output:
+	// {Bar:2 Baz:hello Foo:1}
+	// {Bar:2 Baz:world Foo:3}
+}
+
+func ExampleReadValue() {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for {
+		record, err := ReadValue(r)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Fatal(err)
+		}
+		fmt.Printf("%+v\n", record)
+	}
+	// Output:
+	// {Bar:2 Baz:hello Foo:1}
+	// {Bar:2 Baz:world Foo:3}
+}
+
+// fuzzType has struct fields of every supported kind of conversion.
+type fuzzType struct {
+	String   string            `csv:"string"`
+	Int      int               `csv:"int,default=1"`
+	Uint     uint8             `csv:"uint"`
+	Float    float64           `csv:"float"`
+	Complex  complex64         `csv:"complex"`
+	Bool     bool              `csv:"bool,true=y,false=n"`
+	Time     time.Time         `csv:"time,layout=2006-01-02"`
+	Duration time.Duration     `csv:"duration"`
+	Pointer  *int              `csv:"pointer,omitempty"`
+	Split    []int             `csv:"split,split=;"`
+	JSON     map[string]any    `csv:"json,json"`
+	Enum     string            `csv:"enum,enumfold,enum=a|b"`
+	Any      any               `csv:"any"`
+	Bytes    []byte            `csv:"bytes"`
+	List     listType          `csv:"list"`
+	Alias    string            `csv:"alias|other"`
+	Rest     []string          `csv:",rest"`
+	Map      map[string]string `csv:"-"`
+}
+
+// Reading arbitrary input returns errors but never panics.
+func FuzzReader(f *testing.F) {
+	f.Add([]byte(exampleCSV))
+	f.Add([]byte("string,int,float,bool,time,split,json,enum,any\nx,2,1.5,y,2024-01-02,1;2,{\"a\":1},A,3\n"))
+	f.Add([]byte("complex,duration,pointer,bytes,list,other\n1+2i,1h,3,abc,\"a,b\",z,extra\n\n\"unterminated\n"))
+	f.Add([]byte("\uFEFFint,int,INT\n1,2,3\n"))
+	optionSets := [][]ReaderOption{
+		nil,
+		{WithCaseInsensitiveHeaders(), WithTrimSpace(), WithNullValues("NULL"), WithRejectNonFinite()},
+		{WithoutHeader(), WithInferTypes(), WithDecimalSeparator(','), WithThousandsSeparator('.')},
+		{WithHeaderLine(2), WithCommentPrefix("#"), WithStrictColumns(), WithLazyQuotes(true)},
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, opts := range optionSets {
+			rd := csv.NewReader(bytes.NewReader(data))
+			rd.FieldsPerRecord = -1
+			r, err := NewReader[*fuzzType](rd, opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			if _, err := r.Peek(); err == io.EOF {
+				continue
+			}
+			for i := 0; i < 100; i++ {
+				var record fuzzType
+				if err := r.Read(&record); err == io.EOF {
+					break
+				}
+				_ = r.Extras()
+			}
+		}
+	})
+}
+
+// benchmarkCSV returns a CSV file with n records of numericType.
+func benchmarkCSV(n int) string {
+	var sb strings.Builder
+	sb.WriteString("name,age,small,count,price,ratio\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "name%d,%d,%d,%d,%d.99,0.5\n", i, i, i%100, i, i)
+	}
+	return sb.String()
+}
+
+func BenchmarkReader_Read(b *testing.B) {
+	data := benchmarkCSV(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
+		if err != nil {
+			b.Fatalf("expected no error for creating reader but got %v", err)
+		}
+		var record numericType
+		for {
+			if err := r.Read(&record); err != nil {
+				if err == io.EOF {
+					break
+				}
+				b.Fatalf("expected no error but got %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkReader_ReadAll(b *testing.B) {
+	data := benchmarkCSV(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
+		if err != nil {
+			b.Fatalf("expected no error for creating reader but got %v", err)
+		}
+		if _, err := r.ReadAll(); err != nil {
+			b.Fatalf("expected no error but got %v", err)
+		}
+	}
+}
+
+// Reading batches into pooled rows allocates no rows, unlike ReadAll.
+func BenchmarkReader_batch(b *testing.B) {
+	data := benchmarkCSV(1000)
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
+			if err != nil {
+				b.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			for {
+				batch := make([]*numericType, 0, 100)
+				n, err := r.ReadInto(batch[:cap(batch)])
+				if err == io.EOF || n == 0 {
+					break
+				}
+				if err != nil {
+					b.Fatalf("expected no error but got %v", err)
+				}
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		pool := sync.Pool{New: func() any {
+			batch := make([]*numericType, 0, 100)
+			return &batch
+		}}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(data)))
+			if err != nil {
+				b.Fatalf("expected no error for creating reader but got %v", err)
+			}
+			for {
+				batch := pool.Get().(*[]*numericType)
+				rows, err := r.ReadBatch(*batch)
+				*batch = rows[:0]
+				pool.Put(batch)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					b.Fatalf("expected no error but got %v", err)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkReader_bufferSize(b *testing.B) {
+	data := benchmarkCSV(10000)
+	for _, size := range []int{0, 1 << 14, 1 << 16, 1 << 20} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				r, err := NewDecoder[*numericType](strings.NewReader(data), WithBufferSize(size))
+				if err != nil {
+					b.Fatalf("expected no error for creating decoder but got %v", err)
+				}
+				var record numericType
+				for {
+					if err := r.Read(&record); err != nil {
+						if err == io.EOF {
+							break
+						}
+						b.Fatalf("expected no error but got %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReader_parseHeader(b *testing.B) {
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader("")))
+	if err != nil {
+		b.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	header := []string{"name", "age", "small", "count", "price", "ratio", "extra"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := r.parseHeader(header); err != nil {
+			b.Fatalf("expected no error for parsing header but got %v", err)
+		}
+	}
+}
+
+// wideCSV returns a CSV file with n records of 200 record fields, of
+// which foo, bar and baz are the 10th, 100th and 190th.
+func wideCSV(n int) string {
+	header := make([]string, 200)
+	for i := range header {
+		header[i] = "col" + strconv.Itoa(i)
+	}
+	header[10], header[100], header[190] = "foo", "bar", "baz"
+	var sb strings.Builder
+	sb.WriteString(strings.Join(header, ",") + "\n")
+	record := strings.Repeat("x,", 199) + "x\n"
+	for i := 0; i < n; i++ {
+		sb.WriteString(record)
+	}
+	return sb.String()
+}
+
+// Unmapped record fields are not in the plan, so they cost nothing.
+func TestReader_assignFieldsWide(t *testing.T) {
+	rd := csv.NewReader(strings.NewReader(wideCSV(1)))
+	rd.ReuseRecord = true
+	r, err := NewReader[*exampleType](rd)
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record exampleType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := 3, len(r.plan); want != got {
+		t.Fatalf("expected %d record fields in the plan but got %d", want, got)
+	}
+	rcd := strings.Split(strings.Repeat("x,", 199)+"x", ",")
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := r.assignFields(rcd, &record); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+	})
+	if want, got := 0.0, allocs; want != got {
+		t.Fatalf("expected %v allocations but got %v", want, got)
+	}
+}
+
+func BenchmarkReader_wide(b *testing.B) {
+	data := wideCSV(1000)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rd := csv.NewReader(strings.NewReader(data))
+		rd.ReuseRecord = true
+		r, err := NewReader[*exampleType](rd)
+		if err != nil {
+			b.Fatalf("expected no error for creating reader but got %v", err)
+		}
+		var record exampleType
+		for {
+			if err := r.Read(&record); err != nil {
+				if err == io.EOF {
+					break
+				}
+				b.Fatalf("expected no error but got %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkReader_assignFields(b *testing.B) {
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader(benchmarkCSV(1))))
+	if err != nil {
+		b.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record numericType
+	if err := r.Read(&record); err != nil {
+		b.Fatalf("expected no error but got %v", err)
+	}
+	rcd := []string{"alice", "42", "-8", "100", "9.99", "0.5"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := r.assignFields(rcd, &record); err != nil {
+			b.Fatalf("expected no error but got %v", err)
 		}
-		fmt.Printf("%+v\n", record)
 	}
-	// Output:
-	// {Bar:2 Baz:hello Foo:1}
-	// {Bar:2 Baz:world Foo:3}
 }
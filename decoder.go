@@ -0,0 +1,49 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+)
+
+// NewDecoder creates a new structured data reader reading CSV from r.
+// It is a convenience wrapper of NewReader with csv.NewReader(r) as the
+// underlying CSV reader, which can be configured with the options, or
+// directly with CSVReader before the first Read. Unlike NewReader, it
+// supports WithBufferSize and WithEncoding.
+func NewDecoder[T any](r io.Reader, opts ...ReaderOption) (*Reader[T], error) {
+	return newReader[T](nil, r, opts)
+}
+
+// NewDecoderGzip creates a new structured data reader reading gzip
+// compressed CSV from r, such as a .csv.gz file. It is the same as
+// NewDecoder with r decompressed by gzip.NewReader. It returns error if
+// r does not start with a valid gzip header. Errors of decompressing
+// the rest of r, such as gzip.ErrChecksum, are returned by Read as a
+// *ReadError.
+func NewDecoderGzip[T any](r io.Reader, opts ...ReaderOption) (*Reader[T], error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewDecoder[T](zr, opts...)
+}
+
+// Unmarshal parses the CSV data and returns every record, each stored
+// in a newly allocated T, like json.Unmarshal for a whole document. It
+// is the same as ReadAll of NewDecoder reading data, so it returns the
+// errors of NewDecoder for an invalid T, and the records before the
+// first error of reading the records.
+func Unmarshal[T any](data []byte, opts ...ReaderOption) ([]T, error) {
+	d, err := NewDecoder[T](bytes.NewReader(data), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return d.ReadAll()
+}
+
+// CSVReader returns the underlying CSV reader.
+func (r *Reader[T]) CSVReader() *csv.Reader {
+	return r.rd
+}
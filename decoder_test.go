@@ -0,0 +1,265 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestNewDecoder(t *testing.T) {
+	d, err := NewDecoder[*exampleType](strings.NewReader(exampleCSV))
+	if err != nil {
+		t.Fatalf("expected no error for creating decoder but got %v", err)
+	}
+	rows, err := d.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}
+	if want, got := len(expected), len(rows); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range expected {
+		if want, got := expected[i], *rows[i]; want != got {
+			t.Fatalf("expecting record %d to be %v but got %v", i, want, got)
+		}
+	}
+}
+
+func TestNewDecoder_validateFields(t *testing.T) {
+	_, err := NewDecoder[exampleType](strings.NewReader(exampleCSV))
+	if want, got := ErrNotPointer, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestNewDecoder_CSVReader(t *testing.T) {
+	d, err := NewDecoder[*exampleType](strings.NewReader("foo;bar;baz\n1;2;hello\n"))
+	if err != nil {
+		t.Fatalf("expected no error for creating decoder but got %v", err)
+	}
+	d.CSVReader().Comma = ';'
+	var record exampleType
+	if err := d.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "1", Bar: "2", Baz: "hello"}), record; want != got {
+		t.Fatalf("expecting %v but got %v", want, got)
+	}
+}
+
+// gzipData returns data compressed with gzip.
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		t.Fatalf("expected no error compressing data but got %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("expected no error compressing data but got %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestNewDecoderGzip(t *testing.T) {
+	d, err := NewDecoderGzip[*exampleType](bytes.NewReader(gzipData(t, exampleCSV)))
+	if err != nil {
+		t.Fatalf("expected no error for creating decoder but got %v", err)
+	}
+	rows, err := d.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}
+	if want, got := len(expected), len(rows); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range expected {
+		if want, got := expected[i], *rows[i]; want != got {
+			t.Fatalf("expecting record %d to be %v but got %v", i, want, got)
+		}
+	}
+}
+
+func TestNewDecoderGzip_errors(t *testing.T) {
+	// Not gzip compressed.
+	_, err := NewDecoderGzip[*exampleType](strings.NewReader(exampleCSV))
+	if want, got := gzip.ErrHeader, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+
+	// Corrupted checksum in the gzip trailer.
+	data := gzipData(t, exampleCSV)
+	data[len(data)-8] ^= 0xff
+	d, err := NewDecoderGzip[*exampleType](bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected no error for creating decoder but got %v", err)
+	}
+	_, err = d.ReadAll()
+	var readErr *ReadError
+	if !errors.As(err, &readErr) {
+		t.Fatalf("expected *ReadError but got %v", err)
+	}
+	if want, got := gzip.ErrChecksum, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+// readSizeReader records the largest buffer it is asked to read into.
+type readSizeReader struct {
+	r       io.Reader
+	maxRead int
+}
+
+func (r *readSizeReader) Read(p []byte) (int, error) {
+	r.maxRead = max(r.maxRead, len(p))
+	return r.r.Read(p)
+}
+
+func TestNewDecoder_bufferSize(t *testing.T) {
+	testCases := [...]struct {
+		name            string
+		opts            []ReaderOption
+		expectedMaxRead int
+	}{
+		{name: "default", expectedMaxRead: 4096},
+		{name: "larger", opts: []ReaderOption{WithBufferSize(1 << 16)}, expectedMaxRead: 1 << 16},
+		// Smaller buffers are bypassed by the default buffer of csv.NewReader.
+		{name: "smaller", opts: []ReaderOption{WithBufferSize(1024)}, expectedMaxRead: 4096},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := &readSizeReader{r: strings.NewReader(benchmarkCSV(10000))}
+			d, err := NewDecoder[*numericType](src, tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating decoder but got %v", err)
+			}
+			rows, err := d.ReadAll()
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := 10000, len(rows); want != got {
+				t.Fatalf("expected %d records but got %d", want, got)
+			}
+			if want, got := tc.expectedMaxRead, src.maxRead; want != got {
+				t.Fatalf("expected reads of at most %d bytes but got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestNewDecoder_encoding(t *testing.T) {
+	// "café,naïve,€5" in Windows-1252.
+	data := "foo,bar,baz\ncaf\xe9,na\xefve,\x805\n"
+	d, err := NewDecoder[*exampleType](strings.NewReader(data), WithEncoding(charmap.Windows1252))
+	if err != nil {
+		t.Fatalf("expected no error for creating decoder but got %v", err)
+	}
+	var record exampleType
+	if err := d.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := (exampleType{Foo: "café", Bar: "naïve", Baz: "€5"}), record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	// Without the option, the bytes are read as is.
+	d, err = NewDecoder[*exampleType](strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected no error for creating decoder but got %v", err)
+	}
+	if err := d.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "caf\xe9", record.Foo; want != got {
+		t.Fatalf("expected %q but got %q", want, got)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	rows, err := Unmarshal[*exampleType]([]byte(exampleCSV))
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []*exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}
+	if want, got := expected, rows; !reflect.DeepEqual(want, got) {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	rows, err = Unmarshal[*exampleType](nil)
+	if err != nil {
+		t.Fatalf("expected no error for empty data but got %v", err)
+	}
+	if want, got := 0, len(rows); want != got {
+		t.Fatalf("expected %d rows but got %d", want, got)
+	}
+}
+
+func TestUnmarshal_errors(t *testing.T) {
+	_, err := Unmarshal[exampleType]([]byte(exampleCSV))
+	if want, got := ErrNotPointer, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+
+	rows, err := Unmarshal[*numericType]([]byte("name,age\nalice,42\nbob,x\n"))
+	var recErr *RecordError
+	if !errors.As(err, &recErr) || recErr.Line != 3 {
+		t.Fatalf("expected *RecordError on line 3 but got %v", err)
+	}
+	if want, got := 1, len(rows); want != got {
+		t.Fatalf("expected %d rows before the error but got %d", want, got)
+	}
+
+	_, err = Unmarshal[*exampleType]([]byte("foo,bar\n\"1,2\n"))
+	var readErr *ReadError
+	if !errors.As(err, &readErr) {
+		t.Fatalf("expected *ReadError but got %v", err)
+	}
+}
+
+func ExampleUnmarshal() {
+	data := []byte("name,age\nalice,42\nbob,7\n")
+	type person struct {
+		Name string `csv:"name"`
+		Age  int    `csv:"age"`
+	}
+	people, err := Unmarshal[*person](data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range people {
+		fmt.Printf("%s is %d\n", p.Name, p.Age)
+	}
+	// Output:
+	// alice is 42
+	// bob is 7
+}
+
+func ExampleNewDecoder() {
+	d, err := NewDecoder[*exampleType](strings.NewReader(exampleCSV))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for {
+		var record exampleType
+		if err := d.Read(&record); err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Fatal(err)
+		}
+		fmt.Printf("%+v\n", record)
+	}
+	// Output:
+	// {Bar:2 Baz:hello Foo:1}
+	// {Bar:2 Baz:world Foo:3}
+}
@@ -0,0 +1,43 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+)
+
+// NewEncoder creates a new structured data writer writing CSV to w.
+// It is a convenience wrapper of NewWriter with csv.NewWriter(w) as the
+// underlying CSV writer, which can be configured directly with
+// CSVWriter before the first Encode. Unlike NewWriter, it supports
+// WithAlwaysQuote.
+func NewEncoder[T any](w io.Writer, opts ...WriterOption) (*Writer[T], error) {
+	return newWriter[T](csv.NewWriter(w), w, opts)
+}
+
+// Encode writes rowPtr as one record, writing the header first if it
+// has not been written. It is the same as Write.
+func (w *Writer[T]) Encode(rowPtr T) error {
+	return w.Write(rowPtr)
+}
+
+// Marshal returns the CSV encoding of rows, with the header followed by
+// one record per row, like json.Marshal for a whole document. It is the
+// same as WriteAll of NewEncoder, so the columns are in the order of
+// NewWriter, and the header is written even if rows is empty.
+func Marshal[T any](rows []T, opts ...WriterOption) ([]byte, error) {
+	var buf bytes.Buffer
+	e, err := NewEncoder[T](&buf, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CSVWriter returns the underlying CSV writer.
+func (w *Writer[T]) CSVWriter() *csv.Writer {
+	return w.w
+}
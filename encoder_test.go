@@ -0,0 +1,228 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestNewEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	e, err := NewEncoder[*exampleType](&buf)
+	if err != nil {
+		t.Fatalf("expected no error for creating encoder but got %v", err)
+	}
+	for _, row := range []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}} {
+		if err := e.Encode(&row); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+	}
+	e.Flush()
+	if err := e.Error(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "bar,baz,foo\n2,hello,1\n2,world,3\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestNewEncoder_validateFields(t *testing.T) {
+	_, err := NewEncoder[exampleType](&bytes.Buffer{})
+	if want, got := ErrNotPointer, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestNewEncoder_CSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	e, err := NewEncoder[*exampleType](&buf)
+	if err != nil {
+		t.Fatalf("expected no error for creating encoder but got %v", err)
+	}
+	e.CSVWriter().Comma = ';'
+	if err := e.Encode(&exampleType{Foo: "1", Bar: "2", Baz: "hello"}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	e.Flush()
+	if want, got := "bar;baz;foo\n2;hello;1\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestNewEncoder_alwaysQuote(t *testing.T) {
+	testCases := [...]struct {
+		name      string
+		configure func(*csv.Writer)
+		row       exampleType
+		expected  string
+	}{
+		{
+			name:     "default",
+			row:      exampleType{Foo: "1", Bar: "", Baz: "hello, world"},
+			expected: "\"bar\",\"baz\",\"foo\"\n\"\",\"hello, world\",\"1\"\n",
+		},
+		{
+			name:     "quotes and newlines",
+			row:      exampleType{Foo: `say "hi"`, Bar: "a\r\nb", Baz: "c\nd"},
+			expected: "\"bar\",\"baz\",\"foo\"\n\"a\r\nb\",\"c\nd\",\"say \"\"hi\"\"\"\n",
+		},
+		{
+			name:      "comma and CRLF",
+			configure: func(w *csv.Writer) { w.Comma, w.UseCRLF = ';', true },
+			row:       exampleType{Foo: "1", Bar: "a\r\nb", Baz: "c\nd"},
+			expected:  "\"bar\";\"baz\";\"foo\"\r\n\"a\r\nb\";\"c\r\nd\";\"1\"\r\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e, err := NewEncoder[*exampleType](&buf, WithAlwaysQuote())
+			if err != nil {
+				t.Fatalf("expected no error for creating encoder but got %v", err)
+			}
+			if tc.configure != nil {
+				tc.configure(e.CSVWriter())
+			}
+			if err := e.WriteAll([]*exampleType{&tc.row}); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := tc.expected, buf.String(); want != got {
+				t.Fatalf("expected output %q but got %q", want, got)
+			}
+
+			d, err := NewDecoder[*exampleType](&buf, WithComma(e.CSVWriter().Comma))
+			if err != nil {
+				t.Fatalf("expected no error for creating decoder but got %v", err)
+			}
+			var record exampleType
+			if err := d.Read(&record); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			// Quoted "\r\n" is read as "\n".
+			expected := tc.row
+			expected.Bar = strings.ReplaceAll(expected.Bar, "\r\n", "\n")
+			if want, got := expected, record; want != got {
+				t.Fatalf("expecting %+v but got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	data, err := Marshal([]*exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}})
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "bar,baz,foo\n2,hello,1\n2,world,3\n", string(data); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+
+	data, err = Marshal[*exampleType](nil, WithAlwaysQuote())
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "\"bar\",\"baz\",\"foo\"\n", string(data); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestMarshal_errors(t *testing.T) {
+	_, err := Marshal([]exampleType{{}})
+	if want, got := ErrNotPointer, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+
+	_, err = Marshal([]*exampleType{{}, nil})
+	if want, got := ErrNilRow, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestMarshal_sharedHeader(t *testing.T) {
+	type sharedType struct {
+		Raw    string `csv:"foo"`
+		Parsed int    `csv:"foo"`
+		Bar    string `csv:"bar"`
+	}
+	rows := []*sharedType{{Raw: "42", Parsed: 42, Bar: "b"}}
+	data, err := Marshal(rows)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	// The header value of both Raw and Parsed is written once.
+	if want, got := "foo,bar\n42,b\n", string(data); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+	got, err := Unmarshal[*sharedType](data)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := len(rows), len(got); want != got {
+		t.Fatalf("expected %d rows but got %d", want, got)
+	}
+	if want, got := *rows[0], *got[0]; want != got {
+		t.Fatalf("expected row %+v but got %+v", want, got)
+	}
+}
+
+func ExampleMarshal() {
+	type person struct {
+		Name string `csv:"name"`
+		Age  int    `csv:"age"`
+	}
+	data, err := Marshal([]*person{{Name: "alice", Age: 42}, {Name: "bob", Age: 7}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(data))
+
+	people, err := Unmarshal[*person](data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range people {
+		fmt.Printf("%+v\n", *p)
+	}
+	// Output:
+	// name,age
+	// alice,42
+	// bob,7
+	// {Name:alice Age:42}
+	// {Name:bob Age:7}
+}
+
+func ExampleNewEncoder() {
+	var buf bytes.Buffer
+	e, err := NewEncoder[*exampleType](&buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := e.Encode(&exampleType{Foo: "1", Bar: "2", Baz: "hello, world"}); err != nil {
+		log.Fatal(err)
+	}
+	e.Flush()
+	if err := e.Error(); err != nil {
+		log.Fatal(err)
+	}
+
+	d, err := NewDecoder[*exampleType](strings.NewReader(buf.String()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for {
+		var record exampleType
+		if err := d.Read(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%+v\n", record)
+	}
+	// Output:
+	// {Bar:2 Baz:hello, world Foo:1}
+}
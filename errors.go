@@ -0,0 +1,168 @@
+package csv
+
+import "fmt"
+
+// Errors returned by NewReader and NewWriter for invalid struct types.
+var (
+	// ErrNotPointer is returned if the row type is not a pointer.
+	ErrNotPointer = fmt.Errorf("fields should be a pointer")
+	// ErrNotStructPointer is returned if the row type is not a pointer
+	// to a struct.
+	ErrNotStructPointer = fmt.Errorf("fields should be a pointer to a struct")
+	// ErrFieldNotAssignable is returned if the type of a struct field
+	// cannot store record field values.
+	ErrFieldNotAssignable = fmt.Errorf("field is not assignable")
+	// ErrFieldNotWritable is returned by NewWriter if the type of a
+	// struct field cannot be written as a record field value, such as a
+	// type that implements Unmarshaler but not Marshaler.
+	ErrFieldNotWritable = fmt.Errorf("field is not writable")
+	// ErrUnexportedField is returned if an unexported struct field is
+	// tagged, since its value cannot be set or read by reflection.
+	ErrUnexportedField = fmt.Errorf("field is unexported")
+	// ErrDuplicateIndex is returned if more than one struct field is
+	// mapped to the same record field of a file without header.
+	ErrDuplicateIndex = fmt.Errorf("record field is mapped to more than one struct field")
+	// ErrDuplicateRest is returned if more than one struct field has
+	// the rest tag option.
+	ErrDuplicateRest = fmt.Errorf("more than one rest field")
+	// ErrDuplicateField is returned if WithHeaderMap maps more than
+	// one header value to the same struct field.
+	ErrDuplicateField = fmt.Errorf("field mapped from more than one header")
+	// ErrUnknownField is returned if an option names a struct field or
+	// header value that does not exist.
+	ErrUnknownField = fmt.Errorf("unknown field")
+	// ErrUnknownOption is returned if a csv tag has an unknown option.
+	ErrUnknownOption = fmt.Errorf("unknown tag option")
+	// ErrInvalidIndex is returned if the index= tag option is not a
+	// non-negative integer.
+	ErrInvalidIndex = fmt.Errorf("invalid index, should be a non-negative integer")
+	// ErrInvalidOrder is returned if the order= tag option is not an
+	// integer.
+	ErrInvalidOrder = fmt.Errorf("invalid order, should be an integer")
+	// ErrInvalidDefault is returned if the default= tag option cannot
+	// be converted to the type of the struct field.
+	ErrInvalidDefault = fmt.Errorf("invalid default value")
+	// ErrInvalidFormat is returned if the fmt= tag option is not a
+	// valid format of the numeric struct field.
+	ErrInvalidFormat = fmt.Errorf("invalid format")
+	// ErrUnsupportedOption is returned by NewReader and NewWriter for
+	// options that require the io.Reader given to NewDecoder or the
+	// io.Writer given to NewEncoder.
+	ErrUnsupportedOption = fmt.Errorf("option requires NewDecoder or NewEncoder")
+)
+
+// Errors returned by Reader for headers incompatible with the struct type.
+var (
+	// ErrDuplicateHeader is returned if the header has the same value
+	// more than once, when matched case-insensitively.
+	ErrDuplicateHeader = fmt.Errorf("duplicate header")
+	// ErrAmbiguousHeader is returned if the header has more than one
+	// of the header values of a struct field.
+	ErrAmbiguousHeader = fmt.Errorf("more than one header of the field")
+	// ErrMissingRequiredColumn is returned if the header does not have
+	// the header value of a struct field with the required tag option.
+	ErrMissingRequiredColumn = fmt.Errorf("required column missing from header")
+	// ErrUnknownColumn is returned with WithDisallowUnknownColumns if
+	// the header has values not mapped to any struct field.
+	ErrUnknownColumn = fmt.Errorf("column not mapped to any field")
+	// ErrHeaderLineNotFound is returned with WithHeaderLine if the file
+	// ends before the header.
+	ErrHeaderLineNotFound = fmt.Errorf("end of file before the header")
+	// ErrHeaderNotRead is returned by Header before the header is read.
+	ErrHeaderNotRead = fmt.Errorf("header not read, call Read first")
+)
+
+// Errors returned by Reader and Writer for invalid records or rows.
+var (
+	// ErrConversion is wrapped by the errors of record field values
+	// that cannot be converted to or from their struct fields.
+	ErrConversion = fmt.Errorf("invalid value")
+	// ErrInvalidBool is returned if a record field value is not one of
+	// the true= or false= literals of a bool struct field.
+	ErrInvalidBool = fmt.Errorf("invalid bool literal")
+	// ErrInvalidEnum is returned if a record field value is not one of
+	// the enum= literals of the struct field.
+	ErrInvalidEnum = fmt.Errorf("value not in enum")
+	// ErrNonFinite is returned with WithRejectNonFinite if a record
+	// field value of a floating-point or complex struct field is NaN or
+	// an infinity.
+	ErrNonFinite = fmt.Errorf("non-finite number")
+	// ErrColumnCount is matched by a *ColumnCountError with errors.Is.
+	ErrColumnCount = fmt.Errorf("wrong number of record fields")
+	// ErrRecordSize is matched by a *RecordSizeError with errors.Is.
+	ErrRecordSize = fmt.Errorf("record too large")
+	// ErrUnknownKey is returned by MapWriter with DisallowUnknownKeys if
+	// a map has keys that are not header values.
+	ErrUnknownKey = fmt.Errorf("key not in header")
+	// ErrNilRow is returned if a nil row is read or written.
+	ErrNilRow = fmt.Errorf("row should not be nil")
+	// ErrInvalidComma is returned with WithAlwaysQuote if the Comma of
+	// the underlying CSV writer is not a valid field delimiter.
+	ErrInvalidComma = fmt.Errorf("invalid comma")
+	// ErrNoRecord is returned by Rows.Scan if Next has not been called
+	// or returned false.
+	ErrNoRecord = fmt.Errorf("no record, call Next first")
+)
+
+// RecordError is returned by Reader when a record field value cannot
+// be stored in its struct field.
+type RecordError struct {
+	Record int    // Record number in the file, starting from 1 (including header)
+	Line   int    // Line where the record field starts, starting from 1
+	Column int    // Column (1-based byte index) where the record field starts
+	Field  string // Name of the struct field
+	Err    error  // The actual error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d (line %d, column %d): field %s: %v", e.Record, e.Line, e.Column, e.Field, e.Err)
+}
+
+func (e *RecordError) Unwrap() error { return e.Err }
+
+// ReadError is returned by Reader when the underlying CSV reader
+// returns an error other than io.EOF, such as a *csv.ParseError.
+type ReadError struct {
+	Record int   // Record number in the file, starting from 1 (including header)
+	Err    error // The error from the underlying CSV reader
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+func (e *ReadError) Unwrap() error { return e.Err }
+
+// ColumnCountError is returned by Reader with the WithStrictColumns
+// option when a record does not have the same number of record fields
+// as the header.
+type ColumnCountError struct {
+	Record   int // Record number in the file, starting from 1 (including header)
+	Line     int // Line where the record starts, starting from 1
+	Expected int // Number of record fields in the header
+	Actual   int // Number of record fields in the record
+}
+
+func (e *ColumnCountError) Error() string {
+	return fmt.Sprintf("record %d (line %d): expected %d record fields but got %d", e.Record, e.Line, e.Expected, e.Actual)
+}
+
+// Is reports whether target is ErrColumnCount.
+func (e *ColumnCountError) Is(target error) bool { return target == ErrColumnCount }
+
+// RecordSizeError is returned by Reader with the WithMaxRecordBytes
+// option when the record field values of a record are more than the
+// maximum number of bytes in total.
+type RecordSizeError struct {
+	Record int // Record number in the file, starting from 1 (including header)
+	Line   int // Line where the record starts, starting from 1
+	Size   int // Number of bytes of the record field values
+	Max    int // Maximum number of bytes of the record field values
+}
+
+func (e *RecordSizeError) Error() string {
+	return fmt.Sprintf("record %d (line %d): %d bytes of record fields exceeds the maximum of %d", e.Record, e.Line, e.Size, e.Max)
+}
+
+// Is reports whether target is ErrRecordSize.
+func (e *RecordSizeError) Is(target error) bool { return target == ErrRecordSize }
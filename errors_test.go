@@ -0,0 +1,150 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// readError returns the error of creating a Reader of T reading data,
+// or of reading its first record.
+func readError[T any](data string, opts ...ReaderOption) error {
+	r, err := NewReader[T](csv.NewReader(strings.NewReader(data)), opts...)
+	if err != nil {
+		return err
+	}
+	return r.Read(r.newRow())
+}
+
+var errMarshal = errors.New("cannot marshal")
+
+// failingMarshaler always fails to marshal.
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalCSV() (string, error) { return "", errMarshal }
+
+func (*failingMarshaler) UnmarshalCSV(string) error { return nil }
+
+// columnCountError returns the error of reading a record longer than
+// the header with WithStrictColumns.
+func columnCountError() error {
+	rd := csv.NewReader(strings.NewReader("foo,bar\n1,2,3\n"))
+	rd.FieldsPerRecord = -1
+	r, err := NewReader[*exampleType](rd, WithStrictColumns())
+	if err != nil {
+		return err
+	}
+	return r.Read(&exampleType{})
+}
+
+// headerError returns the error of Header of a Reader before Read.
+func headerError() error {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		return err
+	}
+	_, err = r.Header()
+	return err
+}
+
+// invalidCommaError returns the error of writing a record with
+// WithAlwaysQuote and an invalid Comma.
+func invalidCommaError() error {
+	w, err := NewEncoder[*exampleType](&bytes.Buffer{}, WithAlwaysQuote())
+	if err != nil {
+		return err
+	}
+	w.CSVWriter().Comma = '"'
+	return w.Write(&exampleType{})
+}
+
+// writeError returns the error of creating a Writer of T, or of
+// writing row with it.
+func writeError[T any](row T, opts ...WriterOption) error {
+	w, err := NewWriter[T](csv.NewWriter(&bytes.Buffer{}), opts...)
+	if err != nil {
+		return err
+	}
+	return w.Write(row)
+}
+
+// Every error path wraps one of the exported sentinel errors.
+func TestErrors_Is(t *testing.T) {
+	type requiredType struct {
+		Foo string `csv:"foo,required"`
+		Bar int    `csv:"bar|baz"`
+		Qux bool   `csv:"qux,true=y,false=n"`
+	}
+	testCases := [...]struct {
+		name        string
+		err         error
+		expectedErr error
+	}{
+		{name: "not pointer", err: readError[exampleType](exampleCSV), expectedErr: ErrNotPointer},
+		{name: "not struct pointer", err: readError[*string](exampleCSV), expectedErr: ErrNotStructPointer},
+		{name: "field not assignable", err: readError[*struct {
+			Foo chan int `csv:"foo"`
+		}](exampleCSV), expectedErr: ErrFieldNotAssignable},
+		{name: "unexported field", err: readError[*struct {
+			foo string `csv:"foo"`
+		}](exampleCSV), expectedErr: ErrUnexportedField},
+		{name: "duplicate index", err: readError[*struct {
+			Foo string `csv:"foo,index=0"`
+			Bar string `csv:"bar,index=0"`
+		}](exampleCSV, WithoutHeader()), expectedErr: ErrDuplicateIndex},
+		{name: "duplicate rest", err: readError[*struct {
+			Rest  []string `csv:",rest"`
+			Rest2 []string `csv:",rest"`
+		}](exampleCSV), expectedErr: ErrDuplicateRest},
+		{name: "duplicate field", err: readError[*exampleType](exampleCSV, WithHeaderMap(map[string]string{"a": "Foo", "b": "Foo"})), expectedErr: ErrDuplicateField},
+		{name: "unknown field", err: readError[*exampleType](exampleCSV, WithHeaderMap(map[string]string{"a": "Qux"})), expectedErr: ErrUnknownField},
+		{name: "unknown option", err: readError[*struct {
+			Foo string `csv:"foo,omitempy"`
+		}](exampleCSV), expectedErr: ErrUnknownOption},
+		{name: "invalid index", err: readError[*struct {
+			Foo string `csv:"foo,index=x"`
+		}](exampleCSV), expectedErr: ErrInvalidIndex},
+		{name: "invalid order", err: readError[*struct {
+			Foo string `csv:"foo,order=x"`
+		}](exampleCSV), expectedErr: ErrInvalidOrder},
+		{name: "invalid default", err: readError[*struct {
+			Foo int `csv:"foo,default=x"`
+		}](exampleCSV), expectedErr: ErrInvalidDefault},
+		{name: "invalid format", err: readError[*struct {
+			Foo int `csv:"foo,fmt=%s"`
+		}](exampleCSV), expectedErr: ErrInvalidFormat},
+		{name: "unsupported reader option", err: readError[*exampleType](exampleCSV, WithBufferSize(1<<16)), expectedErr: ErrUnsupportedOption},
+		{name: "unsupported encoding option", err: readError[*exampleType](exampleCSV, WithEncoding(charmap.Windows1252)), expectedErr: ErrUnsupportedOption},
+		{name: "unsupported writer option", err: writeError(&exampleType{}, WithAlwaysQuote()), expectedErr: ErrUnsupportedOption},
+		{name: "duplicate header", err: readError[*exampleType]("foo,FOO\n1,2\n", WithCaseInsensitiveHeaders()), expectedErr: ErrDuplicateHeader},
+		{name: "ambiguous header", err: readError[*requiredType]("foo,bar,baz\n1,2,3\n"), expectedErr: ErrAmbiguousHeader},
+		{name: "missing required column", err: readError[*requiredType]("bar\n1\n"), expectedErr: ErrMissingRequiredColumn},
+		{name: "unknown column", err: readError[*exampleType]("foo,quux\n1,2\n", WithDisallowUnknownColumns()), expectedErr: ErrUnknownColumn},
+		{name: "header line not found", err: readError[*exampleType]("foo\n", WithHeaderLine(2)), expectedErr: ErrHeaderLineNotFound},
+		{name: "conversion", err: readError[*requiredType]("foo,bar\n1,x\n"), expectedErr: ErrConversion},
+		{name: "invalid bool", err: readError[*requiredType]("foo,qux\n1,x\n"), expectedErr: ErrInvalidBool},
+		{name: "invalid bool conversion", err: readError[*requiredType]("foo,qux\n1,x\n"), expectedErr: ErrConversion},
+		{name: "column count", err: columnCountError(), expectedErr: ErrColumnCount},
+		{name: "record size", err: readError[*exampleType](exampleCSV, WithMaxRecordBytes(4)), expectedErr: ErrRecordSize},
+		{name: "header not read", err: headerError(), expectedErr: ErrHeaderNotRead},
+		{name: "nil row", err: writeError[*exampleType](nil), expectedErr: ErrNilRow},
+		{name: "invalid comma", err: invalidCommaError(), expectedErr: ErrInvalidComma},
+		{name: "field not writable", err: writeError(&struct {
+			Point readOnlyType `csv:"point"`
+		}{}), expectedErr: ErrFieldNotWritable},
+		{name: "marshal", err: writeError(&struct {
+			Value failingMarshaler `csv:"value"`
+		}{}), expectedErr: ErrConversion},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if want, got := tc.expectedErr, tc.err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+		})
+	}
+}
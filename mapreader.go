@@ -0,0 +1,84 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// MapReader is a reader from CSV that reads each record as a map from
+// header values to record field values, for reading CSV without a
+// struct type.
+type MapReader struct {
+	rd           *csv.Reader // Underlying CSV reader
+	header       []string
+	parsedHeader bool
+	records      int // Number of records read from rd
+}
+
+// NewMapReader creates a new map reader from an underlying raw CSV
+// record reader. The first record is the header.
+func NewMapReader(r *csv.Reader) *MapReader {
+	return &MapReader{rd: r}
+}
+
+// readRecord reads a record with the underlying CSV reader, removing
+// the UTF-8 byte order mark at the start of the file if any.
+// Errors other than io.EOF are returned as a *ReadError.
+func (r *MapReader) readRecord() ([]string, error) {
+	rcd, err := r.rd.Read()
+	if rcd != nil {
+		r.records++
+	}
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		readErr := &ReadError{Record: r.records, Err: err}
+		if rcd == nil {
+			readErr.Record++ // The record could not be read.
+		}
+		return nil, readErr
+	}
+	if r.records == 1 && len(rcd) > 0 {
+		rcd[0] = strings.TrimPrefix(rcd[0], bom)
+	}
+	return rcd, nil
+}
+
+// Read reads one record as a map keyed by the header values.
+// Record fields without a header value are ignored, and header values
+// without a record field are not in the map.
+// If the header has duplicate values, the last record field is used.
+// It returns io.EOF if there's no more record to read.
+func (r *MapReader) Read() (map[string]string, error) {
+	if !r.parsedHeader {
+		header, err := r.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		r.header = append([]string(nil), header...)
+		r.parsedHeader = true
+	}
+	rcd, err := r.readRecord()
+	if err != nil {
+		return nil, err
+	}
+	row := make(map[string]string, len(r.header))
+	for i, field := range rcd {
+		if i >= len(r.header) {
+			break
+		}
+		row[r.header[i]] = field
+	}
+	return row, nil
+}
+
+// Header returns the header values in the order of the file.
+// It returns error if the header has not been read by Read.
+func (r *MapReader) Header() ([]string, error) {
+	if !r.parsedHeader {
+		return nil, ErrHeaderNotRead
+	}
+	return r.header, nil
+}
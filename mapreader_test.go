@@ -0,0 +1,108 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestMapReader(t *testing.T) {
+	r := NewMapReader(csv.NewReader(strings.NewReader(exampleCSV)))
+	if _, err := r.Header(); !errors.Is(err, ErrHeaderNotRead) {
+		t.Fatalf("expected error %v but got %v", ErrHeaderNotRead, err)
+	}
+	expected := []map[string]string{
+		{"foo": "1", "bar": "2", "baz": "hello"},
+		{"foo": "3", "bar": "2", "baz": "world"},
+	}
+	for i := range expected {
+		row, err := r.Read()
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if want, got := expected[i], row; !maps.Equal(want, got) {
+			t.Fatalf("expecting record %d to be %v but got %v", i, want, got)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+	header, err := r.Header()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "foo,bar,baz", strings.Join(header, ","); want != got {
+		t.Fatalf("expected header %s but got %s", want, got)
+	}
+}
+
+func TestMapReader_recordLength(t *testing.T) {
+	rd := csv.NewReader(strings.NewReader("foo,bar\n1\n1,2,3\n"))
+	rd.FieldsPerRecord = -1
+	r := NewMapReader(rd)
+	expected := []map[string]string{{"foo": "1"}, {"foo": "1", "bar": "2"}}
+	for i := range expected {
+		row, err := r.Read()
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if want, got := expected[i], row; !maps.Equal(want, got) {
+			t.Fatalf("expecting record %d to be %v but got %v", i, want, got)
+		}
+	}
+}
+
+// Empty and header-only files are read the same way as Reader.
+func TestMapReader_empty(t *testing.T) {
+	for _, data := range []string{"", "foo,bar,baz\n"} {
+		r := NewMapReader(csv.NewReader(strings.NewReader(data)))
+		if _, err := r.Read(); err != io.EOF {
+			t.Fatalf("expected EOF error for %q but got %v", data, err)
+		}
+	}
+}
+
+func TestMapReader_BOM(t *testing.T) {
+	r := NewMapReader(csv.NewReader(strings.NewReader("\uFEFF" + exampleCSV)))
+	row, err := r.Read()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "1", row["foo"]; want != got {
+		t.Fatalf("expected foo to be %s but got %s", want, got)
+	}
+}
+
+func TestMapReader_readError(t *testing.T) {
+	r := NewMapReader(csv.NewReader(strings.NewReader("foo,bar\n1,2\"\n")))
+	_, err := r.Read()
+	var readErr *ReadError
+	if !errors.As(err, &readErr) {
+		t.Fatalf("expected read error but got %v", err)
+	}
+	if want, got := 2, readErr.Record; want != got {
+		t.Fatalf("expected error in record %d but got %d", want, got)
+	}
+}
+
+func ExampleMapReader() {
+	r := NewMapReader(csv.NewReader(strings.NewReader(exampleCSV)))
+	for {
+		row, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Fatal(err)
+		}
+		fmt.Println(row["foo"], row["baz"])
+	}
+	// Output:
+	// 1 hello
+	// 3 world
+}
@@ -0,0 +1,79 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// MapWriter is a writer to CSV that writes each record from a map from
+// header values to record field values, for writing CSV without a
+// struct type.
+type MapWriter struct {
+	// DisallowUnknownKeys makes Write return ErrUnknownKey if a map has
+	// keys that are not header values, instead of ignoring them.
+	DisallowUnknownKeys bool
+
+	w           *csv.Writer // Underlying CSV writer
+	header      []string
+	columns     map[string]bool // Header values
+	wroteHeader bool
+}
+
+// NewMapWriter creates a new map writer to an underlying raw CSV record
+// writer. The header is the first record, and it sets the order of the
+// record fields.
+func NewMapWriter(w *csv.Writer, header []string) *MapWriter {
+	columns := make(map[string]bool, len(header))
+	for _, value := range header {
+		columns[value] = true
+	}
+	return &MapWriter{w: w, header: slices.Clone(header), columns: columns}
+}
+
+// WriteHeader writes the header row. It is called automatically by the
+// first Write if the header has not been written.
+func (w *MapWriter) WriteHeader() error {
+	if err := w.w.Write(w.header); err != nil {
+		return err
+	}
+	w.wroteHeader = true
+	return nil
+}
+
+// Write writes row as one record in the order of the header, writing
+// the header first if it has not been written. Header values that are
+// not keys of row are written as empty record field values, and keys
+// that are not header values are ignored, unless DisallowUnknownKeys
+// is set.
+func (w *MapWriter) Write(row map[string]string) error {
+	if w.DisallowUnknownKeys {
+		for _, key := range slices.Sorted(maps.Keys(row)) {
+			if !w.columns[key] {
+				return fmt.Errorf("key %q: %w", key, ErrUnknownKey)
+			}
+		}
+	}
+	if !w.wroteHeader {
+		if err := w.WriteHeader(); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(w.header))
+	for i, value := range w.header {
+		record[i] = row[value]
+	}
+	return w.w.Write(record)
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *MapWriter) Flush() {
+	w.w.Flush()
+}
+
+// Error reports any error that has occurred during
+// a previous Write or Flush.
+func (w *MapWriter) Error() error {
+	return w.w.Error()
+}
@@ -0,0 +1,103 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"testing"
+)
+
+func TestMapWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewMapWriter(csv.NewWriter(&buf), []string{"foo", "bar", "baz"})
+	rows := []map[string]string{
+		{"foo": "1", "bar": "2", "baz": "hello"},
+		{"baz": "world", "foo": "3"},
+		{"foo": "4", "qux": "ignored"},
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "foo,bar,baz\n1,2,hello\n3,,world\n4,,\n", buf.String(); want != got {
+		t.Fatalf("expected %q but got %q", want, got)
+	}
+}
+
+// A MapReader reads the maps written by a MapWriter.
+func TestMapWriter_roundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewMapWriter(csv.NewWriter(&buf), []string{"foo", "bar", "baz"})
+	rows := []map[string]string{
+		{"foo": "1", "bar": "2", "baz": "hello, \"world\""},
+		{"foo": "3", "bar": "", "baz": "multi\nline"},
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	r := NewMapReader(csv.NewReader(&buf))
+	for i := range rows {
+		row, err := r.Read()
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if want, got := rows[i], row; !maps.Equal(want, got) {
+			t.Fatalf("expecting record %d to be %v but got %v", i, want, got)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Fatalf("expected EOF error but got %v", err)
+	}
+}
+
+func TestMapWriter_disallowUnknownKeys(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewMapWriter(csv.NewWriter(&buf), []string{"foo", "bar"})
+	w.DisallowUnknownKeys = true
+	if err := w.Write(map[string]string{"foo": "1"}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := ErrUnknownKey, w.Write(map[string]string{"foo": "2", "qux": "3"}); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	w.Flush()
+	// The row with an unknown key is not written.
+	if want, got := "foo,bar\n1,\n", buf.String(); want != got {
+		t.Fatalf("expected %q but got %q", want, got)
+	}
+}
+
+func ExampleMapWriter() {
+	w := NewMapWriter(csv.NewWriter(os.Stdout), []string{"foo", "bar", "baz"})
+	for _, row := range []map[string]string{
+		{"foo": "1", "bar": "2", "baz": "hello"},
+		{"foo": "3", "baz": "world"},
+	} {
+		if err := w.Write(row); err != nil {
+			log.Fatal(err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// foo,bar,baz
+	// 1,2,hello
+	// 3,,world
+}
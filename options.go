@@ -0,0 +1,584 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+)
+
+// ReaderOption configures a Reader.
+type ReaderOption func(*readerOptions)
+
+// readerOptions is the configuration of a Reader.
+type readerOptions struct {
+	noHeader               bool
+	caseInsensitive        bool
+	headerNormalizer       func(string) string
+	strictColumns          bool
+	maxRecordBytes         int
+	trimSpace              bool
+	unquoteHeaders         bool
+	skipLines              int
+	bufferSize             int
+	encoding               encoding.Encoding
+	headerLine             int
+	disallowUnknownColumns bool
+	transforms             []fieldTransform
+	inferTypes             bool
+	rejectNonFinite        bool
+	decimalSeparator       rune
+	thousandsSeparator     rune
+	newlines               *strings.Replacer // Replaces newlines in record field values, or nil
+	nullValues             []string
+	nullFold               bool
+	commentPrefix          string
+	recordErrorHandler     func(line int, record []string, err error) error
+	logger                 func(Event)         // Receives the events of WithLogger, or nil
+	headerMap              map[string]string   // Header value to struct field name
+	configure              []func(*csv.Reader) // Configures the underlying CSV reader
+}
+
+// fieldTransform is a transform function of the WithFieldTransform option.
+type fieldTransform struct {
+	header    string
+	transform func(string) string
+}
+
+// WithoutHeader configures the Reader to read files without a header
+// row. The first record is treated as data, and struct fields are
+// mapped to record fields by the index= tag option, or otherwise by
+// the position of the field among the tagged struct fields.
+//
+// For example, given
+//
+//	type Row struct {
+//		Foo string `csv:"foo"`         // record field 0
+//		Bar string `csv:"bar,index=3"` // record field 3
+//		Baz string `csv:"baz"`         // record field 2
+//	}
+//
+// It is an error to map more than one struct field to a record field.
+func WithoutHeader() ReaderOption {
+	return func(o *readerOptions) {
+		o.noHeader = true
+	}
+}
+
+// WithCaseInsensitiveHeaders configures the Reader to match header
+// values to the csv tags of struct fields case-insensitively. It
+// affects only the matching, record field values are stored as is.
+// Reading a header with values that are only different in case
+// returns error.
+func WithCaseInsensitiveHeaders() ReaderOption {
+	return func(o *readerOptions) {
+		o.caseInsensitive = true
+	}
+}
+
+// WithHeaderNormalizer configures the Reader to match header values to
+// the csv tags of struct fields after normalising both with normalize,
+// for example to remove diacritics or to apply Unicode normalisation
+// such as NFC. It affects only the matching, like
+// WithCaseInsensitiveHeaders, which is applied after normalize if both
+// are set. Header values are normalised after WithTrimSpace. Reading a
+// header with values that are the same after normalisation returns
+// error.
+func WithHeaderNormalizer(normalize func(string) string) ReaderOption {
+	return func(o *readerOptions) {
+		o.headerNormalizer = normalize
+	}
+}
+
+// headerKey returns the key of the header value s to match header
+// values and the header values of struct fields, with the options of
+// WithHeaderNormalizer and WithCaseInsensitiveHeaders.
+func (o *readerOptions) headerKey(s string) string {
+	if o.headerNormalizer != nil {
+		s = o.headerNormalizer(s)
+	}
+	if o.caseInsensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// WithComma sets the field delimiter of the underlying CSV reader.
+// See csv.Reader.Comma.
+func WithComma(comma rune) ReaderOption {
+	return func(o *readerOptions) {
+		o.configure = append(o.configure, func(rd *csv.Reader) { rd.Comma = comma })
+	}
+}
+
+// Format is a preset of the field delimiter of a delimited format,
+// for WithFormat.
+type Format rune
+
+// Presets of common delimited formats.
+const (
+	FormatCSV  Format = ','  // Comma-separated values
+	FormatTSV  Format = '\t' // Tab-separated values
+	FormatPipe Format = '|'  // Pipe-separated values
+)
+
+// WithFormat sets the field delimiter of the underlying CSV reader to
+// the one of format. It is the same as WithComma with the delimiter,
+// which remains the option for other delimiters.
+func WithFormat(format Format) ReaderOption {
+	return WithComma(rune(format))
+}
+
+// WithComment sets the comment character of the underlying CSV reader.
+// See csv.Reader.Comment.
+func WithComment(comment rune) ReaderOption {
+	return func(o *readerOptions) {
+		o.configure = append(o.configure, func(rd *csv.Reader) { rd.Comment = comment })
+	}
+}
+
+// WithCommentPrefix configures the Reader to skip the records after the
+// header whose first record field value starts with prefix, for
+// comments of more than one character such as "//". Unlike WithComment,
+// the comment lines are parsed as CSV by the underlying CSV reader
+// before they are skipped. So they may have any number of record
+// fields, but they should otherwise be valid CSV, e.g. quotes in a
+// comment require WithLazyQuotes. A quoted first record field value
+// that starts with prefix is also skipped, because the quotes are
+// removed by the underlying CSV reader.
+func WithCommentPrefix(prefix string) ReaderOption {
+	return func(o *readerOptions) {
+		o.commentPrefix = prefix
+	}
+}
+
+// isComment returns whether the record is a comment of WithCommentPrefix.
+func (o *readerOptions) isComment(record []string) bool {
+	return o.commentPrefix != "" && len(record) > 0 && strings.HasPrefix(record[0], o.commentPrefix)
+}
+
+// WithLazyQuotes sets whether the underlying CSV reader allows lazy
+// quotes. See csv.Reader.LazyQuotes.
+func WithLazyQuotes(lazyQuotes bool) ReaderOption {
+	return func(o *readerOptions) {
+		o.configure = append(o.configure, func(rd *csv.Reader) { rd.LazyQuotes = lazyQuotes })
+	}
+}
+
+// WithStrictColumns configures the Reader to return a *ColumnCountError
+// if a record does not have the same number of record fields as the
+// header. For files without header, the number of record fields of the
+// first record is used instead.
+//
+// By default, missing record fields leave their struct fields unchanged
+// and extra record fields are ignored.
+func WithStrictColumns() ReaderOption {
+	return func(o *readerOptions) {
+		o.strictColumns = true
+	}
+}
+
+// WithMaxRecordBytes configures the Reader to return a *RecordSizeError
+// if the record field values of a record are more than n bytes in
+// total, before they are stored, e.g. to reject records of untrusted
+// files that would be expensive to convert. The record has already been
+// read by the underlying CSV reader, which has no such limit, so the
+// size of the input should also be limited, e.g. with io.LimitReader.
+// The header is not checked. Non-positive n disables the check, which
+// is the default.
+func WithMaxRecordBytes(n int) ReaderOption {
+	return func(o *readerOptions) {
+		o.maxRecordBytes = n
+	}
+}
+
+// WithHeaderMap configures the Reader to map the header values (the
+// keys of headerMap) to the struct fields with the given names (the
+// values of headerMap), overriding the header values of their csv tags.
+// This allows reading into struct types without csv tags, or files with
+// header values different from the tags.
+//
+// NewReader returns error if a struct field name is not a field of T.
+// The header map can be replaced later with SetSchema.
+func WithHeaderMap(headerMap map[string]string) ReaderOption {
+	return func(o *readerOptions) {
+		o.headerMap = headerMap
+	}
+}
+
+// WithTrimSpace configures the Reader to remove leading and trailing
+// white space of record field values, before they are converted to the
+// types of their struct fields. White space of header values is also
+// removed before they are matched to the csv tags of struct fields.
+func WithTrimSpace() ReaderOption {
+	return func(o *readerOptions) {
+		o.trimSpace = true
+	}
+}
+
+// WithUnquoteHeaders configures the Reader to remove a pair of double
+// quotes surrounding a header value before it is matched to the csv
+// tags of struct fields, for headers whose quotes are kept by the
+// underlying CSV reader, e.g. `"""foo"""`, or ` "foo"` with lazy quotes.
+// The quotes are removed after the white space of WithTrimSpace. Record
+// field values are not changed.
+func WithUnquoteHeaders() ReaderOption {
+	return func(o *readerOptions) {
+		o.unquoteHeaders = true
+	}
+}
+
+// WithSkipLines configures the Reader to discard the first n records of
+// the file, such as banner or metadata rows, before reading the header
+// (or the first record for files without header). The discarded records
+// may have any number of record fields.
+func WithSkipLines(n int) ReaderOption {
+	return func(o *readerOptions) {
+		o.skipLines = n
+	}
+}
+
+// WithBufferSize configures the Reader to read the io.Reader of
+// NewDecoder through a bufio.Reader of size bytes, instead of the
+// default buffer of csv.NewReader. Larger buffers make fewer reads of
+// slow sources, such as network connections, at the cost of memory.
+// Sizes smaller than the default 4096 bytes have no effect, because
+// csv.NewReader buffers the reads again.
+//
+// The option is only supported by NewDecoder and NewDecoderGzip, and
+// NewReader returns ErrUnsupportedOption. It has no effect on the CSV
+// readers given to Reset.
+func WithBufferSize(size int) ReaderOption {
+	return func(o *readerOptions) {
+		o.bufferSize = size
+	}
+}
+
+// WithEncoding configures the Reader to decode the io.Reader given to
+// NewDecoder from enc to UTF-8 before it is parsed as CSV, for files
+// of legacy systems such as charmap.Windows1252 of
+// golang.org/x/text/encoding/charmap. Without the option, the input is
+// read as UTF-8.
+//
+// The option is only supported by NewDecoder and NewDecoderGzip, and
+// NewReader returns ErrUnsupportedOption. It has no effect on the CSV
+// readers given to Reset.
+func WithEncoding(enc encoding.Encoding) ReaderOption {
+	return func(o *readerOptions) {
+		o.encoding = enc
+	}
+}
+
+// WithFieldTransform configures the Reader to apply transform to the
+// record field values of the struct fields with the header value header,
+// for example to normalise the values with strings.ToUpper. The header
+// value is the one from the csv tag, or from WithHeaderMap.
+//
+// A record field value is first trimmed by WithTrimSpace, then emptied
+// if it is null by WithNullValues, then its newlines are replaced by
+// WithReplaceNewlines, then it is transformed, then its number
+// separators are normalised by WithDecimalSeparator and
+// WithThousandsSeparator, then replaced by the default value if empty,
+// and finally converted to the type of the struct field. More than one
+// transform of the same struct field are applied in the order of the
+// options.
+//
+// NewReader returns error if no struct field has the header value.
+func WithFieldTransform(header string, transform func(string) string) ReaderOption {
+	return func(o *readerOptions) {
+		o.transforms = append(o.transforms, fieldTransform{header: header, transform: transform})
+	}
+}
+
+// WithDisallowUnknownColumns configures the Reader to return error when
+// reading a header with values that are not mapped to any struct field.
+// The error lists all such header values. Empty header values are
+// allowed. This has no effect on files without header.
+func WithDisallowUnknownColumns() ReaderOption {
+	return func(o *readerOptions) {
+		o.disallowUnknownColumns = true
+	}
+}
+
+// WithInferTypes configures the Reader to store record field values in
+// interface struct fields, such as any, as int, float64, bool or string
+// inferred from their content. Without this option, interface struct
+// fields always store a string. The rules are checked in order:
+//
+//   - int if the value is a decimal integer in the canonical form of
+//     strconv.Itoa, i.e. with an optional "-" and without leading
+//     zeros, e.g. "42" and "-7" but not "007", "+7" or "-0".
+//   - float64 if the value is a decimal number with an optional "-",
+//     fraction and exponent, without leading zeros in the integer part,
+//     e.g. "1.50", "-0.5", "1e3" and integers that overflow int, but
+//     not ".5", "1.", "NaN", "Inf" or "0x1p-2".
+//   - bool if the value is "true", "True", "TRUE", "false", "False" or
+//     "FALSE". The other literals of strconv.ParseBool, such as "t" and
+//     "0", are not bool.
+//   - string otherwise, including the empty value.
+func WithInferTypes() ReaderOption {
+	return func(o *readerOptions) {
+		o.inferTypes = true
+	}
+}
+
+// WithRejectNonFinite configures the Reader to return a *RecordError
+// wrapping ErrNonFinite for record field values of floating-point and
+// complex struct fields that strconv parses as NaN or an infinity, such
+// as "NaN", "Inf" and "-Infinity", leaving the struct field unchanged.
+// By default, such values are stored like strconv.ParseFloat.
+func WithRejectNonFinite() ReaderOption {
+	return func(o *readerOptions) {
+		o.rejectNonFinite = true
+	}
+}
+
+// WithHeaderLine configures the Reader to read the header from the nth
+// record of the file, counting from 1, and discard all the records
+// before it like WithSkipLines, which it overrides. Unlike WithSkipLines,
+// Read returns error instead of io.EOF if the file ends before the
+// header. This has no effect on files without header.
+func WithHeaderLine(n int) ReaderOption {
+	return func(o *readerOptions) {
+		o.headerLine = n
+	}
+}
+
+// WithReplaceNewlines configures the Reader to replace the newlines
+// ("\r\n" or "\n") in record field values, which are allowed in quoted
+// record fields, with replacement. By default, newlines are kept.
+func WithReplaceNewlines(replacement string) ReaderOption {
+	return func(o *readerOptions) {
+		o.newlines = strings.NewReplacer("\r\n", replacement, "\n", replacement)
+	}
+}
+
+// WithDecimalSeparator configures the Reader to parse the record field
+// values of numeric struct fields with sep as the decimal separator
+// instead of '.', e.g. WithDecimalSeparator(',') for "1234,56".
+//
+// Separators are normalised after WithTrimSpace, so white space around
+// the numbers requires WithTrimSpace. Default values in tags are not
+// normalised and should always use '.'.
+func WithDecimalSeparator(sep rune) ReaderOption {
+	return func(o *readerOptions) {
+		o.decimalSeparator = sep
+	}
+}
+
+// WithThousandsSeparator configures the Reader to remove sep from the
+// record field values of numeric struct fields before they are parsed,
+// e.g. WithThousandsSeparator(',') for "1,234.56", or together with
+// WithDecimalSeparator(',') and WithThousandsSeparator('.') for
+// "1.234,56". See WithDecimalSeparator for the interaction with
+// WithTrimSpace.
+func WithThousandsSeparator(sep rune) ReaderOption {
+	return func(o *readerOptions) {
+		o.thousandsSeparator = sep
+	}
+}
+
+// WithNullValues configures the Reader to treat record field values
+// equal to any of values, such as "NULL", "N/A" or "-", as empty. The
+// values are matched after WithTrimSpace, and the order of the other
+// changes to record field values is documented in WithFieldTransform.
+//
+// A null record field value is replaced by the default value of its
+// struct field if the tag has the default= option, or leaves the struct
+// field unchanged if the tag has the omitempty option. Otherwise, the
+// struct field is set to its zero value, so a pointer struct field is
+// set to nil and, unlike an empty record field value, a numeric struct
+// field is set to 0 without error.
+func WithNullValues(values ...string) ReaderOption {
+	return func(o *readerOptions) {
+		o.nullValues, o.nullFold = values, false
+	}
+}
+
+// WithNullValuesFold is like WithNullValues, but the values are matched
+// case-insensitively, e.g. "null" and "Null" match "NULL".
+func WithNullValuesFold(values ...string) ReaderOption {
+	return func(o *readerOptions) {
+		o.nullValues, o.nullFold = values, true
+	}
+}
+
+// isNull reports whether s is one of the values of WithNullValues.
+func (o *readerOptions) isNull(s string) bool {
+	for _, value := range o.nullValues {
+		if s == value || (o.nullFold && strings.EqualFold(s, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRecordErrorHandler configures the Reader to call handler when a
+// record cannot be stored in a row, instead of returning the error from
+// Read. The handler is called with the line where the record starts,
+// the record and the error. If handler returns nil, the record is
+// skipped and Read continues with the next record; otherwise Read
+// returns the error from handler. This allows importing the valid
+// records of a file while logging the invalid ones.
+//
+// With WithStrictColumns, the number of record fields is checked before
+// any record field is stored, so a record with the wrong number of
+// record fields is passed to handler with a *ColumnCountError, otherwise
+// the error is a *RecordError of the first record field that cannot be
+// stored. Errors of the underlying CSV reader, such as a *ReadError of
+// malformed CSV, and errors of the header are always returned by Read.
+//
+// The record may be reused by the underlying CSV reader if its
+// ReuseRecord is set, so handler should copy it to retain it.
+func WithRecordErrorHandler(handler func(line int, record []string, err error) error) ReaderOption {
+	return func(o *readerOptions) {
+		o.recordErrorHandler = handler
+	}
+}
+
+// numberReplacer returns a replacer to normalise numbers with the
+// separators of WithDecimalSeparator and WithThousandsSeparator to the
+// syntax of strconv, or nil if the separators are not set.
+func (o *readerOptions) numberReplacer() *strings.Replacer {
+	var oldnew []string
+	if o.thousandsSeparator != 0 {
+		oldnew = append(oldnew, string(o.thousandsSeparator), "")
+	}
+	if o.decimalSeparator != 0 && o.decimalSeparator != '.' {
+		oldnew = append(oldnew, string(o.decimalSeparator), ".")
+	}
+	if len(oldnew) == 0 {
+		return nil
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// EventKind is the kind of an Event.
+type EventKind int
+
+// Kinds of events of WithLogger.
+const (
+	// EventDefaulted is a struct field set to the value of its default=
+	// tag option, for an empty or missing record field value.
+	EventDefaulted EventKind = iota + 1
+	// EventNull is a struct field set to its zero value for a null
+	// record field value of WithNullValues.
+	EventNull
+	// EventUnmatched is a tagged struct field not mapped to any record
+	// field by the header.
+	EventUnmatched
+	// EventSkipped is a record skipped by the handler of
+	// WithRecordErrorHandler.
+	EventSkipped
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventDefaulted:
+		return "defaulted"
+	case EventNull:
+		return "null"
+	case EventUnmatched:
+		return "unmatched"
+	case EventSkipped:
+		return "skipped"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event is an event of reading a file reported to the logger of
+// WithLogger.
+type Event struct {
+	Kind  EventKind
+	Line  int    // Line of the record, or of the header for EventUnmatched
+	Field string // Name of the struct field, or empty for EventSkipped
+	Err   error  // Error of the record for EventSkipped, or nil
+}
+
+// WithLogger configures the Reader to call logger with an Event when a
+// struct field is set to its default value, or to its zero value for a
+// null value, when a tagged struct field is not in the header, and when
+// a record is skipped, e.g. to log the data quality issues of a file
+// with log/slog. The logger is called synchronously by Read, so it
+// should not block, and the events of a record stored by Peek are
+// reported again by the next Read. Without the option, no event is
+// reported.
+func WithLogger(logger func(Event)) ReaderOption {
+	return func(o *readerOptions) {
+		o.logger = logger
+	}
+}
+
+// WriterOption configures a Writer.
+type WriterOption func(*writerOptions)
+
+// writerOptions is the configuration of a Writer.
+type writerOptions struct {
+	alwaysQuote bool
+	columns     []string          // Header values of the columns to write, or nil for all
+	headerNames map[string]string // Header value to the label written in the header
+	autoFlush   int
+}
+
+// WithAlwaysQuote configures the Writer to quote every record field
+// value, including the header and empty values, which the underlying
+// csv.Writer only quotes when necessary. The Comma and UseCRLF of the
+// underlying csv.Writer are used as usual.
+//
+// A value is quoted by wrapping it in '"' and doubling every '"' in
+// it, e.g. `say "hi"` is written as `"say ""hi"""`. Newlines in values
+// are written as is, or as "\r\n" if UseCRLF is set.
+//
+// The quoted records are written directly to the io.Writer, so the
+// option is only supported by NewEncoder, and NewWriter returns
+// ErrUnsupportedOption.
+func WithAlwaysQuote() WriterOption {
+	return func(o *writerOptions) {
+		o.alwaysQuote = true
+	}
+}
+
+// WithColumns configures the Writer to write only the columns with the
+// given header values, in the given order, instead of every tagged
+// struct field. The header values are matched to the header values of
+// the csv tags of the struct fields, excluding aliases. If more than one
+// struct field has a header value, the first one is written.
+//
+// NewWriter returns error if no struct field has one of the header
+// values.
+func WithColumns(headers ...string) WriterOption {
+	return func(o *writerOptions) {
+		o.columns = headers
+	}
+}
+
+// WithHeaderNames configures the Writer to write the header with the
+// labels of names, which maps header values of the csv tags of the
+// struct fields to the labels, e.g. to write a file read with one set
+// of header values with another. Only the header row is changed: the
+// columns and their order are the same, including with WithColumns,
+// which still selects the columns by the header values of the tags.
+// Header values not in names are written as is.
+//
+// NewWriter returns error if no struct field has one of the header
+// values of names.
+func WithHeaderNames(names map[string]string) WriterOption {
+	return func(o *writerOptions) {
+		o.headerNames = names
+	}
+}
+
+// WithAutoFlush configures the Writer to flush the underlying writer
+// after every n records written by Write, so that the records of a long
+// export reach the io.Writer regularly, rather than only when the buffer
+// of the underlying writer is full. The error of flushing is returned by
+// the Write that flushes. The records written since the last automatic
+// flush are only written by Flush, so callers must still call Flush
+// after the last Write. Non-positive n disables automatic flushing,
+// which is the default.
+func WithAutoFlush(n int) WriterOption {
+	return func(o *writerOptions) {
+		o.autoFlush = n
+	}
+}
@@ -0,0 +1,65 @@
+package csv
+
+import "io"
+
+// Rows is an iterator over the records of a Reader in the style of
+// database/sql.Rows:
+//
+//	rows := r.Rows()
+//	for rows.Next() {
+//		var row Row
+//		if err := rows.Scan(&row); err != nil {
+//			// Handle the invalid record, or continue with the next one.
+//		}
+//	}
+//	if err := rows.Err(); err != nil {
+//		// Handle the error of reading.
+//	}
+//
+// Rows does not use the handler of WithRecordErrorHandler, errors of
+// storing a record are returned by Scan instead.
+type Rows[T any] struct {
+	r   *Reader[T]
+	rcd []string // Record read by the last Next, or nil
+	err error    // Error that ended the iteration, or nil
+}
+
+// Rows returns an iterator over the remaining records of r.
+func (r *Reader[T]) Rows() *Rows[T] {
+	return &Rows[T]{r: r}
+}
+
+// Next reads the next record for Scan, reading the header first if
+// needed. It returns false at the end of the file or on error, which
+// is reported by Err.
+func (rs *Rows[T]) Next() bool {
+	rs.rcd = nil
+	if rs.err != nil {
+		return false
+	}
+	rcd, err := rs.r.next()
+	if err != nil {
+		rs.err = err
+		return false
+	}
+	rs.rcd = rcd
+	return true
+}
+
+// Scan stores the record read by the last Next in rowPtr, like Read.
+// It returns ErrNoRecord if Next has not been called or returned false.
+func (rs *Rows[T]) Scan(rowPtr T) error {
+	if rs.rcd == nil {
+		return ErrNoRecord
+	}
+	return rs.r.store(rs.rcd, rowPtr)
+}
+
+// Err returns the error that ended the iteration, or nil if the
+// iteration reached the end of the file.
+func (rs *Rows[T]) Err() error {
+	if rs.err == io.EOF {
+		return nil
+	}
+	return rs.err
+}
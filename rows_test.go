@@ -0,0 +1,94 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	r, err := NewReader[*numericType](csv.NewReader(strings.NewReader("name,age\nalice,42\nbob,x\ncarol,7\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows := r.Rows()
+	if want, got := ErrNoRecord, rows.Scan(&numericType{}); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	var names []string
+	var scanErrs int
+	for rows.Next() {
+		var row numericType
+		if err := rows.Scan(&row); err != nil {
+			// Invalid records do not end the iteration.
+			var recErr *RecordError
+			if !errors.As(err, &recErr) || recErr.Line != 3 {
+				t.Fatalf("expected *RecordError on line 3 but got %v", err)
+			}
+			scanErrs++
+			continue
+		}
+		names = append(names, row.Name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("expected no error at the end of the file but got %v", err)
+	}
+	if want, got := "alice,carol", strings.Join(names, ","); want != got {
+		t.Fatalf("expected rows %s but got %s", want, got)
+	}
+	if want, got := 1, scanErrs; want != got {
+		t.Fatalf("expected %d scan errors but got %d", want, got)
+	}
+	if rows.Next() {
+		t.Fatalf("expected no more records after the end of the file")
+	}
+	if want, got := ErrNoRecord, rows.Scan(&numericType{}); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestRows_readError(t *testing.T) {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader("foo,bar\n1,2\n\"3\n")))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	rows := r.Rows()
+	records := 0
+	for rows.Next() {
+		records++
+	}
+	if want, got := 1, records; want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	var readErr *ReadError
+	if err := rows.Err(); !errors.As(err, &readErr) {
+		t.Fatalf("expected *ReadError but got %v", err)
+	}
+	if rows.Next() {
+		t.Fatalf("expected no more records after error")
+	}
+}
+
+func ExampleRows() {
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	rows := r.Rows()
+	for rows.Next() {
+		var record exampleType
+		if err := rows.Scan(&record); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%+v\n", record)
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// {Bar:2 Baz:hello Foo:1}
+	// {Bar:2 Baz:world Foo:3}
+}
@@ -1,21 +1,183 @@
 package csv
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
 // Tag represents a "csv" struct field tag.
 //
 // For example, `csv:"field_name"` is represented as Tag{FieldName: "field_name"}
+//
+// As a special case, if the header value is "-", the field is always
+// skipped, e.g. `csv:"-"` or `csv:"-,omitempty"`.
 type Tag struct {
-	// FieldHeader is the CSV header value of the field.
+	// FieldHeader is the CSV header value of the field. An empty
+	// FieldHeader means the field is not mapped to any header value.
+	// If the header has the same value more than once, FieldHeader
+	// matches the first occurrence, and the nth occurrence is matched by
+	// the value followed by "#n", e.g. `csv:"value#2"` for the second
+	// "value".
 	FieldHeader string
-	Options     string
+	// Aliases are the alternative header values of the field, which
+	// follow FieldHeader separated by "|", e.g. `csv:"postal_code|zip"`.
+	Aliases []string
+	Options string
+
+	// True is the "|"-separated literals accepted as true for a bool
+	// field, set with the true= option, e.g. `csv:"active,true=yes|y"`.
+	True string
+	// False is the "|"-separated literals accepted as false for a bool
+	// field, set with the false= option.
+	False string
+	// Layout is the time layout of a time.Time field, set with the
+	// layout= option, e.g. `csv:"created_at,layout=2006-01-02"`. It is
+	// used both to read and to write the field, and it defaults to
+	// time.RFC3339.
+	Layout string
+	// Index is the record field index of the field for files without
+	// header, set with the index= option, e.g. `csv:"name,index=2"`.
+	// For files with header, it is the fallback if none of the header
+	// values of the field is in the header. The header values of all
+	// struct fields take precedence over the index of any struct field.
+	// It is only valid if HasIndex is true.
+	Index    int
+	HasIndex bool
+	// Required is set by the required option, and it requires the
+	// header to have the FieldHeader value.
+	Required bool
+	// OmitEmpty is set by the omitempty option. When reading, an empty
+	// record field value leaves the struct field unchanged. When
+	// writing, a zero value struct field is written as an empty
+	// record field value.
+	OmitEmpty bool
+	// Skip is set if the header value is "-", and the field is not
+	// mapped to any record field.
+	Skip bool
+	// Default is the value used for an empty or missing record field,
+	// set with the default= option, e.g. `csv:"country,default=US"`.
+	Default string
+	// JSON is whether the record field value is JSON, set with the json
+	// option, e.g. `csv:"metadata,json"`. The value is unmarshalled into
+	// the field with json.Unmarshal, so the field can be of any type
+	// supported by encoding/json, such as a struct, map or slice.
+	JSON bool
+	// Split is the separator of the values of a slice field, set with
+	// the split= option, e.g. `csv:"tags,split=;"`.
+	Split string
+	// Order is the position of the column of the field when written by
+	// a Writer, set with the order= option, e.g. `csv:"name,order=2"`.
+	// HasOrder is whether the option is set.
+	Order    int
+	HasOrder bool
+	// Rest is whether the field stores the record fields beyond the
+	// header, set with the rest option, e.g. `csv:",rest"`. The field
+	// should be a []string, and it is not mapped by header value. Records
+	// longer than the header are only read if the FieldsPerRecord of the
+	// underlying csv.Reader is negative.
+	Rest bool
+	// Enum is the "|"-separated literals allowed as the record field
+	// value, set with the enum= option, e.g.
+	// `csv:"status,enum=active|inactive|pending"`.
+	// EnumFold is whether the literals are matched case-insensitively
+	// and ignoring leading and trailing white space, set with the
+	// enumfold option. The field stores the literal as written in the tag.
+	Enum     string
+	EnumFold bool
+	// Format is the fmt package format of a numeric field when written
+	// by a Writer, set with the fmt= option, e.g. `csv:"price,fmt=%.2f"`.
+	// The format should have exactly one verb for the value.
+	Format string
 }
 
 // ParseTag parses a raw struct tag (`csv:"tag,value,value2"`)
 // and returns a Tag representing its content.
 func ParseTag(tag string) Tag {
 	name, opts, _ := strings.Cut(tag, ",")
-	return Tag{FieldHeader: name, Options: opts}
+	if name == "-" {
+		return Tag{Options: opts, Skip: true}
+	}
+	name, aliases, _ := strings.Cut(name, "|")
+	t := Tag{FieldHeader: name, Options: opts}
+	for _, alias := range strings.Split(aliases, "|") {
+		if alias != "" {
+			t.Aliases = append(t.Aliases, alias)
+		}
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "true":
+			t.True = value
+		case "false":
+			t.False = value
+		case "layout":
+			t.Layout = value
+		case "default":
+			t.Default = value
+		case "omitempty":
+			t.OmitEmpty = true
+		case "required":
+			t.Required = true
+		case "json":
+			t.JSON = true
+		case "rest":
+			t.Rest = true
+		case "enum":
+			t.Enum = value
+		case "enumfold":
+			t.EnumFold = true
+		case "split":
+			t.Split = value
+		case "fmt":
+			t.Format = value
+		case "order":
+			if order, err := strconv.Atoi(value); err == nil {
+				t.Order, t.HasOrder = order, true
+			}
+		case "index":
+			if index, err := strconv.Atoi(value); err == nil && index >= 0 {
+				t.Index, t.HasIndex = index, true
+			}
+		}
+	}
+	return t
+}
+
+// checkOptions checks the raw options of a tag, which ParseTag ignores
+// if they are not valid. It returns all the problems found joined.
+func checkOptions(opts string) error {
+	if opts == "" {
+		return nil
+	}
+	var errs []error
+	for _, opt := range strings.Split(opts, ",") {
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "true", "false", "layout", "default", "omitempty", "required", "json", "split", "rest", "enum", "enumfold", "fmt":
+		case "index":
+			if index, err := strconv.Atoi(value); err != nil || index < 0 {
+				errs = append(errs, fmt.Errorf("index %q: %w", value, ErrInvalidIndex))
+			}
+		case "order":
+			if _, err := strconv.Atoi(value); err != nil {
+				errs = append(errs, fmt.Errorf("order %q: %w", value, ErrInvalidOrder))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("option %q: %w", opt, ErrUnknownOption))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Headers returns the non-empty header values of the field,
+// which are FieldHeader followed by Aliases.
+func (t Tag) Headers() []string {
+	var headers []string
+	if t.FieldHeader != "" {
+		headers = append(headers, t.FieldHeader)
+	}
+	return append(headers, t.Aliases...)
 }
@@ -1,6 +1,12 @@
 package csv
 
-import "testing"
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestParseTag(t *testing.T) {
 	tcs := [...]struct {
@@ -11,17 +17,107 @@ func TestParseTag(t *testing.T) {
 		{name: "empty", tag: "", expectedTag: Tag{}},
 		{name: "just header", tag: "field_header", expectedTag: Tag{FieldHeader: "field_header"}},
 		{name: "empty header", tag: ",", expectedTag: Tag{FieldHeader: ""}},
-		{name: "header with other unrecognised options", tag: "field_header,omitempty,irrelevant", expectedTag: Tag{FieldHeader: "field_header", Options: "omitempty,irrelevant"}},
+		{name: "header with other unrecognised options", tag: "field_header,omitempty,irrelevant", expectedTag: Tag{FieldHeader: "field_header", Options: "omitempty,irrelevant", OmitEmpty: true}},
+		{name: "time layout", tag: "created_at,layout=2006-01-02", expectedTag: Tag{FieldHeader: "created_at", Options: "layout=2006-01-02", Layout: "2006-01-02"}},
+		{name: "index", tag: "name,index=2", expectedTag: Tag{FieldHeader: "name", Options: "index=2", Index: 2, HasIndex: true}},
+		{name: "index without header", tag: ",index=0", expectedTag: Tag{Options: "index=0", Index: 0, HasIndex: true}},
+		{name: "invalid index", tag: "name,index=-1", expectedTag: Tag{FieldHeader: "name", Options: "index=-1"}},
+		{name: "required", tag: "user_id,required", expectedTag: Tag{FieldHeader: "user_id", Options: "required", Required: true}},
+		{name: "skip", tag: "-", expectedTag: Tag{Skip: true}},
+		{name: "skip with options", tag: "-,omitempty", expectedTag: Tag{Options: "omitempty", Skip: true}},
+		{name: "default", tag: "country,default=US", expectedTag: Tag{FieldHeader: "country", Options: "default=US", Default: "US"}},
+		{name: "aliases", tag: "postal_code|zip|,required", expectedTag: Tag{FieldHeader: "postal_code", Aliases: []string{"zip"}, Options: "required", Required: true}},
+		{name: "json", tag: "metadata,json", expectedTag: Tag{FieldHeader: "metadata", Options: "json", JSON: true}},
+		{name: "split", tag: "tags,split=;", expectedTag: Tag{FieldHeader: "tags", Options: "split=;", Split: ";"}},
+		{name: "order", tag: "name,order=2", expectedTag: Tag{FieldHeader: "name", Options: "order=2", Order: 2, HasOrder: true}},
+		{name: "invalid order", tag: "name,order=x", expectedTag: Tag{FieldHeader: "name", Options: "order=x"}},
+		{name: "rest", tag: ",rest", expectedTag: Tag{Options: "rest", Rest: true}},
+		{name: "enum", tag: "status,enum=active|inactive,enumfold", expectedTag: Tag{FieldHeader: "status", Options: "enum=active|inactive,enumfold", Enum: "active|inactive", EnumFold: true}},
+		{name: "fmt", tag: "price,fmt=%.2f", expectedTag: Tag{FieldHeader: "price", Options: "fmt=%.2f", Format: "%.2f"}},
+		{name: "bool literals", tag: "active,true=yes|y,false=no", expectedTag: Tag{FieldHeader: "active", Options: "true=yes|y,false=no", True: "yes|y", False: "no"}},
 	}
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(tag string, expectedTag Tag) func(*testing.T) {
 			return func(t *testing.T) {
 				parsed := ParseTag(tag)
-				if want, got := expectedTag, parsed; want != got {
+				if want, got := expectedTag, parsed; !reflect.DeepEqual(want, got) {
 					t.Fatalf("expected tag `%s` to be parsed as %+v but got %+v", tag, want, got)
 				}
 			}
 		}(tc.tag, tc.expectedTag))
 	}
 }
+
+func TestTag_Headers(t *testing.T) {
+	tcs := [...]struct {
+		name            string
+		tag             string
+		expectedHeaders []string
+	}{
+		{name: "empty", tag: ""},
+		{name: "just header", tag: "zip", expectedHeaders: []string{"zip"}},
+		{name: "aliases", tag: "postal_code|zip|post_code", expectedHeaders: []string{"postal_code", "zip", "post_code"}},
+		{name: "aliases without header", tag: "|zip", expectedHeaders: []string{"zip"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if want, got := tc.expectedHeaders, ParseTag(tc.tag).Headers(); !reflect.DeepEqual(want, got) {
+				t.Fatalf("expected headers %q but got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestCheckOptions(t *testing.T) {
+	tcs := [...]struct {
+		name        string
+		opts        string
+		expectedErr error
+	}{
+		{name: "empty", opts: ""},
+		{name: "valid", opts: "omitempty,required,index=2,default=x,layout=2006,true=y,false=n"},
+		{name: "unknown", opts: "omitempy", expectedErr: ErrUnknownOption},
+		{name: "negative index", opts: "index=-1", expectedErr: ErrInvalidIndex},
+		{name: "non-numeric index", opts: "index=a", expectedErr: ErrInvalidIndex},
+		{name: "invalid order", opts: "order=1.5", expectedErr: ErrInvalidOrder},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if want, got := tc.expectedErr, checkOptions(tc.opts); !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+		})
+	}
+}
+
+// ParseTag never panics, and a tag written back from a Tag parses to
+// the same Tag.
+func FuzzParseTag(f *testing.F) {
+	for _, tag := range []string{"", "-", "-,omitempty", "name", "postal_code|zip|,required", "name,index=2,order=-1", "active,true=yes|y,false=no", "tags,split=;,default=a;b", "-|", "|alias,"} {
+		f.Add(tag)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		tag := ParseTag(raw)
+		name, opts, _ := strings.Cut(raw, ",")
+		if want, got := opts, tag.Options; want != got {
+			t.Fatalf("expected options %q but got %q", want, got)
+		}
+		if want, got := name == "-", tag.Skip; want != got {
+			t.Fatalf("expected skip %v but got %v", want, got)
+		}
+		if strings.Contains(tag.FieldHeader, "|") || slices.Contains(tag.Aliases, "") {
+			t.Fatalf("invalid header values %q", tag.Headers())
+		}
+		header := strings.Join(append([]string{tag.FieldHeader}, tag.Aliases...), "|")
+		if tag.Skip {
+			header = "-"
+		} else if header == "-" {
+			header = "-|" // Not the skip header value.
+		}
+		if want, got := tag, ParseTag(header+","+tag.Options); !reflect.DeepEqual(want, got) {
+			t.Fatalf("expected %+v but got %+v", want, got)
+		}
+		_ = checkOptions(tag.Options)
+	})
+}
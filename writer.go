@@ -0,0 +1,276 @@
+package csv
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"unicode/utf8"
+)
+
+// Writer is a structured data writer to CSV.
+type Writer[T any] struct {
+	w           *csv.Writer  // Underlying CSV writer
+	quote       *quoteWriter // Writer of WithAlwaysQuote, or nil
+	fields      []column     // Struct field of each record field
+	header      []string
+	wroteHeader bool
+	autoFlush   int // Records to write between flushes of WithAutoFlush, or 0
+	unflushed   int // Records written since the last flush of WithAutoFlush
+}
+
+// NewWriter creates a new structured data writer to an underlying
+// raw CSV record writer. It returns error if the generic type T is
+// not a valid type to write data from, or if a column cannot be
+// written, e.g. a struct field that implements Unmarshaler but not
+// Marshaler.
+//
+// Records are written with one field per tagged struct field. Struct
+// fields with the order= tag option are written first, in ascending
+// order (and in declaration order for equal orders), followed by the
+// other struct fields in the order they are declared. If more than one
+// struct field has the same header value, only the first of them in
+// that order is written. WithColumns selects the columns to write
+// instead.
+func NewWriter[T any](w *csv.Writer, opts ...WriterOption) (*Writer[T], error) {
+	return newWriter[T](w, nil, opts)
+}
+
+// newWriter creates a new structured data writer to w. The io.Writer
+// out is the one w writes to, if known, and it is used by the options
+// that write directly.
+func newWriter[T any](w *csv.Writer, out io.Writer, opts []WriterOption) (*Writer[T], error) {
+	var o writerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var rowPtr T
+	rowPtrType := reflect.TypeOf(rowPtr)
+	if err := validateRowType(rowPtrType); err != nil {
+		return nil, err
+	}
+	csvWriter := &Writer[T]{w: w, autoFlush: o.autoFlush}
+	if o.alwaysQuote {
+		if out == nil {
+			return nil, fmt.Errorf("WithAlwaysQuote: %w", ErrUnsupportedOption)
+		}
+		csvWriter.quote = &quoteWriter{w: bufio.NewWriter(out), csv: w}
+	}
+	rowStruct := rowPtrType.Elem()
+	var columns []column
+	for _, f := range structFields(rowStruct) {
+		tag := ParseTag(f.Tag.Get("csv"))
+		if tag.FieldHeader == "" {
+			continue
+		}
+		columns = append(columns, column{name: f.Name, index: f.Index, tag: tag})
+	}
+	for _, header := range slices.Sorted(maps.Keys(o.headerNames)) {
+		if !slices.ContainsFunc(columns, func(c column) bool { return c.tag.FieldHeader == header }) {
+			return nil, fmt.Errorf("header name of %q: %w", header, ErrUnknownField)
+		}
+	}
+	slices.SortStableFunc(columns, func(a, b column) int {
+		switch {
+		case a.tag.HasOrder && b.tag.HasOrder:
+			return cmp.Compare(a.tag.Order, b.tag.Order)
+		case a.tag.HasOrder:
+			return -1
+		case b.tag.HasOrder:
+			return 1
+		}
+		return 0
+	})
+	// Struct fields with the same header value are all read from the
+	// same record field, so only the first one is written.
+	written := make(map[string]bool)
+	columns = slices.DeleteFunc(columns, func(c column) bool {
+		duplicate := written[c.tag.FieldHeader]
+		written[c.tag.FieldHeader] = true
+		return duplicate
+	})
+	if o.columns != nil {
+		byHeader := make(map[string]column)
+		for _, c := range columns {
+			byHeader[c.tag.FieldHeader] = c
+		}
+		columns = columns[:0]
+		for _, header := range o.columns {
+			c, exists := byHeader[header]
+			if !exists {
+				return nil, fmt.Errorf("column %q: %w", header, ErrUnknownField)
+			}
+			columns = append(columns, c)
+		}
+	}
+	for _, c := range columns {
+		if !isWritableField(rowStruct.FieldByIndex(c.index).Type, c.tag) {
+			return nil, fmt.Errorf("invalid field %s: %w", c.name, ErrFieldNotWritable)
+		}
+		csvWriter.fields = append(csvWriter.fields, c)
+		label, renamed := o.headerNames[c.tag.FieldHeader]
+		if !renamed {
+			label = c.tag.FieldHeader
+		}
+		csvWriter.header = append(csvWriter.header, label)
+	}
+	return csvWriter, nil
+}
+
+// column is a struct field written as a record field, with its parsed
+// tag cached.
+type column struct {
+	name  string
+	index []int // Index sequence in T, see structFields
+	tag   Tag
+}
+
+// WriteHeader writes the header row, which consists of the header
+// values of the tagged struct fields. It is called automatically by
+// the first Write if the header has not been written.
+func (w *Writer[T]) WriteHeader() error {
+	if err := w.writeRecord(w.header); err != nil {
+		return err
+	}
+	w.wroteHeader = true
+	return nil
+}
+
+// Write writes rowPtr as one record, writing the header first if it
+// has not been written. With WithAutoFlush, it also flushes the
+// underlying writer every n records, and returns the error of flushing.
+func (w *Writer[T]) Write(rowPtr T) error {
+	rowValue := reflect.ValueOf(rowPtr)
+	if rowValue.IsNil() {
+		return ErrNilRow
+	}
+	if !w.wroteHeader {
+		if err := w.WriteHeader(); err != nil {
+			return err
+		}
+	}
+	rowStruct := rowValue.Elem()
+	record := make([]string, len(w.fields))
+	for i, c := range w.fields {
+		v, err := rowStruct.FieldByIndexErr(c.index)
+		if err != nil {
+			continue // In a nil embedded struct, write as empty record field value.
+		}
+		if c.tag.OmitEmpty && v.IsZero() {
+			continue // Write as empty record field value.
+		}
+		field, err := formatValue(v, c.tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w: %w", c.name, ErrConversion, err)
+		}
+		record[i] = field
+	}
+	if err := w.writeRecord(record); err != nil {
+		return err
+	}
+	if w.autoFlush > 0 {
+		w.unflushed++
+		if w.unflushed >= w.autoFlush {
+			w.Flush()
+			return w.Error()
+		}
+	}
+	return nil
+}
+
+// writeRecord writes record with the underlying CSV writer, or quoted
+// with WithAlwaysQuote.
+func (w *Writer[T]) writeRecord(record []string) error {
+	if w.quote != nil {
+		return w.quote.Write(record)
+	}
+	return w.w.Write(record)
+}
+
+// WriteAll writes the header if it has not been written, then writes
+// every row in rows and flushes the underlying writer. It stops at the
+// first row that cannot be written and reports the index of the row.
+func (w *Writer[T]) WriteAll(rows []T) error {
+	if !w.wroteHeader {
+		if err := w.WriteHeader(); err != nil {
+			return err
+		}
+	}
+	for i, rowPtr := range rows {
+		if err := w.Write(rowPtr); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer[T]) Flush() {
+	w.unflushed = 0
+	if w.quote != nil {
+		w.quote.Flush()
+	}
+	w.w.Flush()
+}
+
+// Error reports any error that has occurred during
+// a previous Write or Flush.
+func (w *Writer[T]) Error() error {
+	if w.quote != nil && w.quote.err != nil {
+		return w.quote.err
+	}
+	return w.w.Error()
+}
+
+// quoteWriter writes records with every record field value quoted,
+// which csv.Writer does not support. It follows the Comma and UseCRLF
+// of csv, the underlying CSV writer of the Writer.
+type quoteWriter struct {
+	w   *bufio.Writer
+	csv *csv.Writer
+	err error // Error of the last Flush
+}
+
+// Write writes a single quoted record to the buffer.
+func (q *quoteWriter) Write(record []string) error {
+	comma := q.csv.Comma
+	if comma == 0 || comma == '"' || comma == '\r' || comma == '\n' || !utf8.ValidRune(comma) || comma == utf8.RuneError {
+		return fmt.Errorf("%w %q", ErrInvalidComma, comma)
+	}
+	for i, field := range record {
+		if i > 0 {
+			q.w.WriteRune(comma)
+		}
+		q.w.WriteByte('"')
+		for j := 0; j < len(field); j++ {
+			switch c := field[j]; {
+			case c == '"':
+				q.w.WriteString(`""`)
+			case c == '\r' && q.csv.UseCRLF:
+				// Dropped like csv.Writer, '\n' is written as "\r\n".
+			case c == '\n' && q.csv.UseCRLF:
+				q.w.WriteString("\r\n")
+			default:
+				q.w.WriteByte(c)
+			}
+		}
+		q.w.WriteByte('"')
+	}
+	var err error
+	if q.csv.UseCRLF {
+		_, err = q.w.WriteString("\r\n")
+	} else {
+		err = q.w.WriteByte('\n')
+	}
+	return err
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (q *quoteWriter) Flush() {
+	q.err = q.w.Flush()
+}
@@ -0,0 +1,717 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter_validateFields(t *testing.T) {
+	// not a pointer
+	_, err := NewWriter[exampleType](csv.NewWriter(&bytes.Buffer{}))
+	if want, got := ErrNotPointer, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	// wrong type
+	_, err = NewWriter[*struct {
+		Field chan int `csv:"field"`
+	}](csv.NewWriter(&bytes.Buffer{}))
+	if want, got := ErrFieldNotAssignable, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	// tagged unexported field
+	_, err = NewWriter[*struct {
+		field string `csv:"field"`
+	}](csv.NewWriter(&bytes.Buffer{}))
+	if want, got := ErrUnexportedField, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*exampleType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	rows := []exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "2", Baz: "world"}}
+	for i := range rows {
+		if err := w.Write(&rows[i]); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	// Columns are in struct field order: Bar Baz Foo
+	if want, got := "bar,baz,foo\n2,hello,1\n2,world,3\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_types(t *testing.T) {
+	type row struct {
+		Name    string    `csv:"name"`
+		Age     int       `csv:"age"`
+		Count   uint8     `csv:"count"`
+		Price   float64   `csv:"price"`
+		Active  bool      `csv:"active"`
+		Custom  bool      `csv:"custom,true=yes|y,false=no"`
+		Created time.Time `csv:"created_at,layout=2006-01-02"`
+		Ignored string
+		Skipped string `csv:"-"`
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter[*row](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.Write(&row{Name: "alice", Age: -3, Count: 7, Price: 9.99, Active: true, Custom: true, Created: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), Ignored: "x", Skipped: "y"}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	w.Flush()
+	if want, got := "name,age,count,price,active,custom,created_at\nalice,-3,7,9.99,true,yes,2023-05-01\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_embedded(t *testing.T) {
+	rows := []*embeddedType{
+		{
+			embeddedAddress: embeddedAddress{embeddedID: embeddedID{ID: 1}, Street: "Main St", City: "Springfield"},
+			EmbeddedContact: &EmbeddedContact{Email: "a@example.com"},
+			embeddedNote:    embeddedNote{Note: "skipped"},
+			embeddedCycle:   embeddedCycle{Name: "c"},
+			Name:            "alice",
+		},
+		// The fields of a nil embedded pointer are written as empty.
+		{embeddedAddress: embeddedAddress{embeddedID: embeddedID{ID: 2}}, Name: "bob"},
+	}
+	out, err := Marshal(rows)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "id,street,city,email,cycle,name\n1,Main St,Springfield,a@example.com,c,alice\n2,,,,,bob\n", string(out); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+// Times read by Reader are written back identically with the same
+// layout= tag options.
+func TestWriter_timeRoundTrip(t *testing.T) {
+	type timeType struct {
+		Date    time.Time  `csv:"date,layout=2006-01-02"`
+		Stamp   time.Time  `csv:"stamp"`
+		Clock   *time.Time `csv:"clock,layout=15:04"`
+		Omitted time.Time  `csv:"omitted,omitempty"`
+	}
+	data := "date,stamp,clock,omitted\n2024-02-29,2024-02-29T13:45:00+09:00,08:30,\n1999-12-31,2000-01-01T00:00:00Z,,2001-02-03T04:05:06-07:00\n"
+	rows, err := Unmarshal[*timeType]([]byte(data))
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), rows[0].Date; !want.Equal(got) {
+		t.Fatalf("expected date %v but got %v", want, got)
+	}
+	out, err := Marshal(rows)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := data, string(out); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+// Rows written by Writer can be read back by Reader.
+func TestWriter_numbers(t *testing.T) {
+	type numberType struct {
+		Int     int        `csv:"int"`
+		Uint    uint8      `csv:"uint"`
+		Float   float64    `csv:"float"`
+		Float32 float32    `csv:"float32"`
+		Price   float64    `csv:"price,fmt=%.2f"`
+		Padded  int        `csv:"padded,fmt=%05d"`
+		Sci     float32    `csv:"sci,fmt=%.3e"`
+		Ptr     *float64   `csv:"ptr,fmt=%.1f"`
+		Complex complex128 `csv:"complex,fmt=%.1f"`
+	}
+	pi := 3.14159
+	testCases := [...]struct {
+		name     string
+		row      numberType
+		expected string
+	}{
+		{
+			name:     "zero",
+			row:      numberType{},
+			expected: "0,0,0,0,0.00,00000,0.000e+00,,(0.0+0.0i)\n",
+		},
+		{
+			name:     "values",
+			row:      numberType{Int: -42, Uint: 255, Float: 1e21, Float32: 0.1, Price: 9.999, Padded: 42, Sci: 12345.678, Ptr: &pi, Complex: 1.25 - 2i},
+			expected: "-42,255,1000000000000000000000,0.1,10.00,00042,1.235e+04,3.1,(1.2-2.0i)\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter[*numberType](csv.NewWriter(&buf))
+			if err != nil {
+				t.Fatalf("expected no error for creating writer but got %v", err)
+			}
+			if err := w.WriteAll([]*numberType{&tc.row}); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			expected := "int,uint,float,float32,price,padded,sci,ptr,complex\n" + tc.expected
+			if want, got := expected, buf.String(); want != got {
+				t.Fatalf("expected output %q but got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestWriter_invalidFormat(t *testing.T) {
+	testCases := [...]struct {
+		name string
+		err  error
+	}{
+		{name: "not numeric", err: writeError(&struct {
+			Name string `csv:"name,fmt=%5s"`
+		}{})},
+		{name: "wrong verb", err: writeError(&struct {
+			Count int `csv:"count,fmt=%s"`
+		}{})},
+		{name: "no verb", err: writeError(&struct {
+			Count int `csv:"count,fmt=n"`
+		}{})},
+		{name: "two verbs", err: writeError(&struct {
+			Price float64 `csv:"price,fmt=%.2f%.2f"`
+		}{})},
+		{name: "duration", err: writeError(&struct {
+			Wait time.Duration `csv:"wait,fmt=%d"`
+		}{})},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if want, got := ErrInvalidFormat, tc.err; !errors.Is(got, want) {
+				t.Fatalf("expected error %v but got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestWriter_roundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*numericType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	expected := numericType{Age: 42, Small: -8, Count: 18446744073709551615, Price: 9.99, Ratio: 0.1, Name: "alice, bob"}
+	if err := w.Write(&expected); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	w.Flush()
+	r, err := NewReader[*numericType](csv.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record numericType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := expected, record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestWriter_namedTypes(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*namedType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	usd := currencyType("USD")
+	expected := namedType{Currency: "GBP", Count: -3, Level: 255, Rate: 0.25, Active: true, Previous: &usd, Accepted: []currencyType{"GBP", "EUR"}, Status: "USD"}
+	if err := w.Write(&expected); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	w.Flush()
+	if want, got := "currency,count,level,rate,active,previous,accepted,status\nGBP,-3,255,0.25,yes,USD,GBP;EUR,USD\n", buf.String(); want != got {
+		t.Fatalf("expected %q but got %q", want, got)
+	}
+	r, err := NewReader[*namedType](csv.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record namedType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := expected, record; !reflect.DeepEqual(want, got) {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestWriter_WriteAll(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*numericType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	rows := []*numericType{{Age: 42, Name: "alice"}, {Age: 7, Price: 1.5, Name: "bob"}}
+	if err := w.WriteAll(rows); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	r, err := NewReader[*numericType](csv.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := len(rows), len(records); want != got {
+		t.Fatalf("expected %d records but got %d", want, got)
+	}
+	for i := range rows {
+		if want, got := *rows[i], *records[i]; want != got {
+			t.Fatalf("record %d: expecting %+v but got %+v", i, want, got)
+		}
+	}
+}
+
+func TestWriter_WriteAllEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*exampleType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.WriteAll(nil); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "bar,baz,foo\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_WriteAllError(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*exampleType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	err = w.WriteAll([]*exampleType{{Foo: "1"}, nil, {Foo: "3"}})
+	if want, got := ErrNilRow, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if want, got := "row 1: ", err.Error(); !strings.HasPrefix(got, want) {
+		t.Fatalf("expected error to start with %q but got %q", want, got)
+	}
+}
+
+func TestWriter_json(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*jsonType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	expected := jsonType{Name: "alice", Metadata: map[string]string{"team": "a"}, Tags: []string{"x"}}
+	if err := w.WriteAll([]*jsonType{&expected}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "name,metadata,tags,point\nalice,\"{\"\"team\"\":\"\"a\"\"}\",\"[\"\"x\"\"]\",\"{\"\"X\"\":0,\"\"Y\"\":0}\"\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+	r, err := NewReader[*jsonType](csv.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record jsonType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := expected, record; !reflect.DeepEqual(want, got) {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestWriter_bytes(t *testing.T) {
+	type bytesType struct {
+		Data []byte `csv:"data"`
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter[*bytesType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	expected := bytesType{Data: []byte("hello, world")}
+	if err := w.WriteAll([]*bytesType{&expected}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	r, err := NewReader[*bytesType](csv.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record bytesType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := expected.Data, record.Data; !bytes.Equal(want, got) {
+		t.Fatalf("expected data %q but got %q", want, got)
+	}
+}
+
+func TestWriter_columns(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*exampleType](csv.NewWriter(&buf), WithColumns("foo", "baz"))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	rows := []*exampleType{{Foo: "1", Bar: "2", Baz: "hello"}, {Foo: "3", Bar: "4", Baz: "world"}}
+	if err := w.WriteAll(rows); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "foo,baz\n1,hello\n3,world\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+	r, err := NewReader[*exampleType](csv.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	expected := []*exampleType{{Foo: "1", Baz: "hello"}, {Foo: "3", Baz: "world"}}
+	if want, got := expected, records; !reflect.DeepEqual(want, got) {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+
+	_, err = NewWriter[*exampleType](csv.NewWriter(&buf), WithColumns("foo", "qux"))
+	if want, got := ErrUnknownField, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestWriter_headerNames(t *testing.T) {
+	testCases := [...]struct {
+		name           string
+		opts           []WriterOption
+		expectedOutput string
+	}{
+		{
+			name:           "renamed",
+			opts:           []WriterOption{WithHeaderNames(map[string]string{"foo": "Foo Label", "baz": "qux"})},
+			expectedOutput: "bar,qux,Foo Label\n2,hello,1\n",
+		},
+		{
+			name:           "with columns",
+			opts:           []WriterOption{WithColumns("foo", "bar"), WithHeaderNames(map[string]string{"foo": "id", "baz": "text"})},
+			expectedOutput: "id,bar\n1,2\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter[*exampleType](csv.NewWriter(&buf), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error for creating writer but got %v", err)
+			}
+			if err := w.WriteAll([]*exampleType{{Foo: "1", Bar: "2", Baz: "hello"}}); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if want, got := tc.expectedOutput, buf.String(); want != got {
+				t.Fatalf("expected output %q but got %q", want, got)
+			}
+		})
+	}
+
+	_, err := NewWriter[*exampleType](csv.NewWriter(&bytes.Buffer{}), WithHeaderNames(map[string]string{"qux": "label"}))
+	if want, got := ErrUnknownField, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+// flushWriter records the data of every write, and fails the writes
+// after the first failAfter writes if failAfter is positive.
+type flushWriter struct {
+	writes    []string
+	failAfter int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *flushWriter) Write(p []byte) (int, error) {
+	if w.failAfter > 0 && len(w.writes) >= w.failAfter {
+		return 0, errWrite
+	}
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestWriter_autoFlush(t *testing.T) {
+	var out flushWriter
+	w, err := NewWriter[*exampleType](csv.NewWriter(&out), WithAutoFlush(2))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := w.Write(&exampleType{Foo: strconv.Itoa(i)}); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+	}
+	expected := []string{"bar,baz,foo\n,,0\n,,1\n", ",,2\n,,3\n"}
+	if want, got := expected, out.writes; !slices.Equal(want, got) {
+		t.Fatalf("expected writes %q but got %q", want, got)
+	}
+	w.Flush()
+	expected = append(expected, ",,4\n")
+	if want, got := expected, out.writes; !slices.Equal(want, got) {
+		t.Fatalf("expected writes %q but got %q", want, got)
+	}
+
+	out = flushWriter{failAfter: 1}
+	w, err = NewWriter[*exampleType](csv.NewWriter(&out), WithAutoFlush(1))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.Write(&exampleType{}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := errWrite, w.Write(&exampleType{}); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+}
+
+func TestWriter_split(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*splitType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.WriteAll([]*splitType{{Tags: []string{"a", "b"}, Scores: []int{1, 2}}, {}}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "tags,scores\na;b,1|2\n,\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_order(t *testing.T) {
+	type orderType struct {
+		A string `csv:"a"`
+		B string `csv:"b,order=2"`
+		C string `csv:"c"`
+		D string `csv:"d,order=-1"`
+		E string `csv:"e,order=2"`
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter[*orderType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.WriteAll([]*orderType{{A: "1", B: "2", C: "3", D: "4", E: "5"}}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	// Ordered fields first, then the others in declaration order.
+	if want, got := "d,b,e,a,c\n4,2,5,1,3\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_duration(t *testing.T) {
+	type durationType struct {
+		Timeout time.Duration `csv:"timeout"`
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter[*durationType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.WriteAll([]*durationType{{Timeout: time.Hour + 15*time.Minute}, {}}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "timeout\n1h15m0s\n0s\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_complex(t *testing.T) {
+	type complexType struct {
+		Z   complex128 `csv:"z"`
+		Z64 complex64  `csv:"z64"`
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter[*complexType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	expected := complexType{Z: 1 + 2i, Z64: -0.5i}
+	if err := w.WriteAll([]*complexType{&expected}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := "z,z64\n(1+2i),(0-0.5i)\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+	r, err := NewReader[*complexType](csv.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating reader but got %v", err)
+	}
+	var record complexType
+	if err := r.Read(&record); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if want, got := expected, record; want != got {
+		t.Fatalf("expecting %+v but got %+v", want, got)
+	}
+}
+
+func TestWriter_pointer(t *testing.T) {
+	type nullableType struct {
+		Age  *int    `csv:"age"`
+		Name *string `csv:"name"`
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter[*nullableType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	age, name := 42, "alice"
+	for _, row := range []*nullableType{{Age: &age, Name: &name}, {}} {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+	}
+	w.Flush()
+	if want, got := "age,name\n42,alice\n,\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_marshaler(t *testing.T) {
+	type marshalerType struct {
+		Name string   `csv:"name"`
+		Tags listType `csv:"tags"`
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter[*marshalerType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.Write(&marshalerType{Name: "alice", Tags: listType{"a", "b"}}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	w.Flush()
+	if want, got := "name,tags\nalice,\"a,b\"\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_omitEmpty(t *testing.T) {
+	type omitEmptyType struct {
+		Name   string    `csv:"name,omitempty"`
+		Age    int       `csv:"age,omitempty"`
+		Active bool      `csv:"active,omitempty"`
+		Date   time.Time `csv:"date,omitempty"`
+		Count  int       `csv:"count"`
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter[*omitEmptyType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.Write(&omitEmptyType{}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	w.Flush()
+	if want, got := "name,age,active,date,count\n,,,,0\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+// readOnlyType can be read from a record field but not written.
+type readOnlyType struct{ X, Y int }
+
+func (p *readOnlyType) UnmarshalCSV(s string) error {
+	_, err := fmt.Sscanf(s, "%d:%d", &p.X, &p.Y)
+	return err
+}
+
+func TestWriter_notWritable(t *testing.T) {
+	type pointRow struct {
+		Name  string       `csv:"name"`
+		Point readOnlyType `csv:"point"`
+	}
+	_, err := NewWriter[*pointRow](csv.NewWriter(&bytes.Buffer{}))
+	if want, got := ErrFieldNotWritable, err; !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	if want, got := "invalid field Point: ", err.Error(); !strings.HasPrefix(got, want) {
+		t.Fatalf("expected error to start with %q but got %q", want, got)
+	}
+
+	// The field is not checked if it is not written.
+	var buf bytes.Buffer
+	w, err := NewWriter[*pointRow](csv.NewWriter(&buf), WithColumns("name"))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if err := w.Write(&pointRow{Name: "a", Point: readOnlyType{X: 1, Y: 2}}); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	w.Flush()
+	if want, got := "name\na\n", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func TestWriter_nilRow(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[*exampleType](csv.NewWriter(&buf))
+	if err != nil {
+		t.Fatalf("expected no error for creating writer but got %v", err)
+	}
+	if want, got := ErrNilRow, w.Write(nil); !errors.Is(got, want) {
+		t.Fatalf("expected error %v but got %v", want, got)
+	}
+	// The header is not written for a nil row.
+	w.Flush()
+	if want, got := "", buf.String(); want != got {
+		t.Fatalf("expected output %q but got %q", want, got)
+	}
+}
+
+func ExampleWriter() {
+	w, err := NewWriter[*exampleType](csv.NewWriter(os.Stdout))
+	if err != nil {
+		log.Fatal(err)
+	}
+	r, err := NewReader[*exampleType](csv.NewReader(strings.NewReader(exampleCSV)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var record exampleType
+	for r.Read(&record) == nil {
+		if err := w.Write(&record); err != nil {
+			log.Fatal(err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// bar,baz,foo
+	// 2,hello,1
+	// 2,world,3
+}